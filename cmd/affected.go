@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var affectedBase string
+
+var affectedCommand = &cobra.Command{
+	Use:   "affected [task-name...]",
+	Short: "Run tasks affected by git changes",
+	Long: `Run the tasks whose x-inputs globs match files changed in git, together with
+the tasks that depend on them. Changed files are those committed since the merge
+base with --base, staged and unstaged changes, and untracked files. Given task
+names, only those tasks are considered.`,
+	Args:              cobra.ArbitraryArgs,
+	ValidArgsFunction: completeTaskLabels(0),
+	RunE:              executeAffectedCommand,
+	SilenceUsage:      true,
+}
+
+func executeAffectedCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	tasksFilePath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to find tasks file: %w", err)
+	}
+
+	tasks, err := loadTasksFor(tasksFilePath, workspaceDir, args)
+	if err != nil {
+		return err
+	}
+
+	var labels []string
+	for _, name := range args {
+		matches, err := lookupTasks(tasks, name)
+		if err != nil {
+			return err
+		}
+		for _, task := range matches {
+			labels = append(labels, task.Label)
+		}
+	}
+
+	gitRoot, err := discovery.FindGitRoot(workspaceDir)
+	if err != nil {
+		return fmt.Errorf("workspace folder %s is not in a git repository", workspaceDir)
+	}
+
+	changed, err := discovery.ChangedFiles(gitRoot, affectedBase)
+	if err != nil {
+		return fmt.Errorf("failed to list changed files: %w", err)
+	}
+	changed = workspaceRelativePaths(changed, gitRoot, workspaceDir)
+
+	if verbose {
+		fmt.Printf("Changed files: %d\n", len(changed))
+		for _, path := range changed {
+			fmt.Printf("  %s\n", path)
+		}
+	}
+
+	affected := selectTasks(affectedTasks(tasks, changed), labels)
+	if len(affected) == 0 {
+		if !quiet {
+			fmt.Println("No affected tasks")
+		}
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("Would execute the following affected tasks:\n")
+		for _, task := range affected {
+			fmt.Printf("  %s\n", task.Label)
+		}
+		return nil
+	}
+
+	if !quiet {
+		for _, task := range affected {
+			fmt.Printf("Executing task: %s\n", task.Label)
+		}
+	}
+
+	cache, err := openCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	return executor.RunAll(context.Background(), affected, tasks, executor.RunOptions{WorkspaceDir: workspaceDir, File: file, Cache: cache, Force: force})
+}
+
+// affectedTasks returns, in tasks.json order, the tasks whose inputs match one
+// of the changed paths and the tasks that depend on them, directly or not.
+func affectedTasks(tasks []config.Task, changed []string) []*config.Task {
+	affected := make(map[string]bool)
+	for i := range tasks {
+		if matchesInputs(&tasks[i], changed) {
+			affected[tasks[i].Label] = true
+		}
+	}
+
+	// Propagate to dependents until nothing changes
+	for updated := true; updated; {
+		updated = false
+		for i := range tasks {
+			if affected[tasks[i].Label] {
+				continue
+			}
+			for _, dep := range tasks[i].GetDependencies() {
+				if affected[dep] {
+					affected[tasks[i].Label] = true
+					updated = true
+					break
+				}
+			}
+		}
+	}
+
+	var result []*config.Task
+	for i := range tasks {
+		if affected[tasks[i].Label] {
+			result = append(result, &tasks[i])
+		}
+	}
+	return result
+}
+
+// matchesInputs reports whether a changed path matches one of the task's
+// x-inputs globs. A glob naming a directory matches everything inside it.
+func matchesInputs(task *config.Task, changed []string) bool {
+	for _, path := range changed {
+		if discovery.MatchAnyPattern(task.Inputs, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTasks keeps the tasks named in labels, or all tasks if labels is empty.
+func selectTasks(tasks []*config.Task, labels []string) []*config.Task {
+	if len(labels) == 0 {
+		return tasks
+	}
+	wanted := make(map[string]bool)
+	for _, label := range labels {
+		wanted[label] = true
+	}
+	var result []*config.Task
+	for _, task := range tasks {
+		if wanted[task.Label] {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// workspaceRelativePaths converts paths relative to gitRoot into paths
+// relative to workspaceDir, dropping those outside of it.
+func workspaceRelativePaths(paths []string, gitRoot, workspaceDir string) []string {
+	var result []string
+	for _, path := range paths {
+		rel, ok := discovery.RelativePath(workspaceDir, filepath.Join(gitRoot, filepath.FromSlash(path)))
+		if ok && rel != "" {
+			result = append(result, rel)
+		}
+	}
+	return result
+}
+
+func init() {
+	affectedCommand.Flags().StringVar(&affectedBase, "base", "HEAD", "git revision to compare against, e.g. origin/main")
+	affectedCommand.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be executed without running")
+	affectedCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	affectedCommand.Flags().BoolVar(&force, "force", false, "run tasks even if their outputs are up to date")
+	affectedCommand.Flags().BoolVar(&noCache, "no-cache", false, "run tasks with \"x-cache\" instead of restoring them from the cache")
+	affectedCommand.Flags().StringVar(&file, "file", "", "file path to replace ${file} variable")
+	rootCmd.AddCommand(affectedCommand)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestAffectedTasks(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "gen", Inputs: []string{"api/*.proto"}},
+		{Label: "build-web", Inputs: []string{"web"}, DependsOn: "gen"},
+		{Label: "build-go", Inputs: []string{"*.go"}, DependsOn: "gen"},
+		{Label: "deploy", DependsOn: []interface{}{"build-web", "build-go"}},
+		{Label: "lint"},
+	}
+
+	tests := []struct {
+		name     string
+		changed  []string
+		expected []string
+	}{
+		{
+			name:     "no changes",
+			changed:  nil,
+			expected: nil,
+		},
+		{
+			name:     "directory input",
+			changed:  []string{"web/src/index.ts"},
+			expected: []string{"build-web", "deploy"},
+		},
+		{
+			name:     "unanchored glob",
+			changed:  []string{"cmd/main.go"},
+			expected: []string{"build-go", "deploy"},
+		},
+		{
+			name:     "dependents of dependents",
+			changed:  []string{"api/service.proto"},
+			expected: []string{"gen", "build-web", "build-go", "deploy"},
+		},
+		{
+			name:     "unrelated file",
+			changed:  []string{"README.md", "api/nested/x.proto"},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var labels []string
+			for _, task := range affectedTasks(tasks, tt.changed) {
+				labels = append(labels, task.Label)
+			}
+			if !reflect.DeepEqual(labels, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, labels)
+			}
+		})
+	}
+}
+
+func TestSelectTasks(t *testing.T) {
+	tasks := []*config.Task{{Label: "a"}, {Label: "b"}, {Label: "c"}}
+
+	if got := selectTasks(tasks, nil); len(got) != 3 {
+		t.Errorf("expected all tasks without labels, got %d", len(got))
+	}
+	got := selectTasks(tasks, []string{"c", "a"})
+	if len(got) != 2 || got[0].Label != "a" || got[1].Label != "c" {
+		t.Errorf("expected a and c in task order, got %v", got)
+	}
+}
+
+func TestWorkspaceRelativePaths(t *testing.T) {
+	gitRoot := filepath.FromSlash("/repo")
+	workspaceDir := filepath.FromSlash("/repo/packages/web")
+
+	got := workspaceRelativePaths([]string{"packages/web/src/a.ts", "packages/api/b.go", "README.md"}, gitRoot, workspaceDir)
+	if !reflect.DeepEqual(got, []string{"src/a.ts"}) {
+		t.Errorf("expected only paths inside the workspace, got %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var noCache bool
+
+var cacheCommand = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and clean the task result cache",
+	Long: `Tasks with "x-cache": true are skipped when a result for the same command,
+inputs and dependency results is found in the cache; their outputs are restored
+and their stdout is replayed instead.`,
+}
+
+var cacheStatusCommand = &cobra.Command{
+	Use:          "status",
+	Short:        "Show the cache entries and which tasks would be restored",
+	Args:         cobra.NoArgs,
+	RunE:         executeCacheStatusCommand,
+	SilenceUsage: true,
+}
+
+var cacheCleanCommand = &cobra.Command{
+	Use:          "clean [task-name...]",
+	Short:        "Remove cache entries of the given tasks, or all of them",
+	Args:         cobra.ArbitraryArgs,
+	RunE:         executeCacheCleanCommand,
+	SilenceUsage: true,
+}
+
+// openCache returns the task result cache of the workspace, or nil if
+// caching is disabled with --no-cache.
+func openCache(workspaceDir string) (*executor.Cache, error) {
+	if noCache {
+		return nil, nil
+	}
+	return workspaceCache(workspaceDir)
+}
+
+// workspaceCache returns the task result cache of the workspace.
+func workspaceCache(workspaceDir string) (*executor.Cache, error) {
+	dir, err := executor.DefaultCacheDir(workspaceDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return executor.NewCache(dir), nil
+}
+
+func executeCacheStatusCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	tasksFilePath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to find tasks file: %w", err)
+	}
+
+	tasks, err := loadAllTasks(tasksFilePath, workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	cache, err := workspaceCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var size int64
+	entriesByLabel := make(map[string]int)
+	for _, entry := range entries {
+		size += entry.Size
+		entriesByLabel[entry.Label]++
+	}
+
+	fmt.Printf("Cache directory: %s\n", cache.Dir())
+	fmt.Printf("Entries: %d (%s)\n", len(entries), formatSize(size))
+
+	opts := executor.RunOptions{WorkspaceDir: workspaceDir, File: file}
+	printed := false
+	for i := range tasks {
+		task := &tasks[i]
+		if !executor.IsCacheable(task) {
+			continue
+		}
+		if !printed {
+			fmt.Println()
+			printed = true
+		}
+		fmt.Printf("  %-30s %-8s entries: %d\n", task.Label, cacheStatus(cache, task, tasks, opts), entriesByLabel[task.Label])
+	}
+	if !printed {
+		fmt.Println()
+		fmt.Println("No task has \"x-cache\": true")
+	}
+	return nil
+}
+
+// cacheStatus tells whether running the task now would restore it from the
+// cache.
+func cacheStatus(cache *executor.Cache, task *config.Task, tasks []config.Task, opts executor.RunOptions) string {
+	key, err := executor.CacheKey(task, tasks, opts)
+	if err != nil {
+		if verbose {
+			fmt.Printf("Failed to compute cache key of '%s': %v\n", task.Label, err)
+		}
+		return "unknown"
+	}
+	if _, ok := cache.Lookup(key); ok {
+		return "cached"
+	}
+	return "stale"
+}
+
+func executeCacheCleanCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	cache, err := workspaceCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	removed, err := cache.Clean(args...)
+	if err != nil {
+		return fmt.Errorf("failed to clean cache: %w", err)
+	}
+	if !quiet {
+		fmt.Printf("Removed %d cache entries\n", removed)
+	}
+	return nil
+}
+
+// formatSize formats a byte count for humans.
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func init() {
+	cacheCommand.PersistentFlags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	cacheCommand.AddCommand(cacheStatusCommand)
+	cacheCommand.AddCommand(cacheCleanCommand)
+	rootCmd.AddCommand(cacheCommand)
+}
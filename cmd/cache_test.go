@@ -0,0 +1,21 @@
+package cmd
+
+import "testing"
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.expected {
+			t.Errorf("formatSize(%d) = %q, expected %q", tt.size, got, tt.expected)
+		}
+	}
+}
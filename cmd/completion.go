@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+// completionFunc is the signature cobra uses for dynamic completions.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completionTasks loads the tasks completions are offered for, honoring the
+// --config and --workspace-folder flags already on the command line.
+func completionTasks() ([]config.Task, error) {
+	tasksPath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadAllTasks(tasksPath, workspaceDir)
+}
+
+// completeTaskLabels completes task labels, including auto-detected tasks, for
+// commands taking up to maxArgs task names, or any number if maxArgs is 0.
+// Labels already given are not offered again.
+func completeTaskLabels(maxArgs int) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if maxArgs > 0 && len(args) >= maxArgs {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		tasks, err := completionTasks()
+		if err != nil {
+			cobra.CompDebugln(err.Error(), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return taskLabelCompletions(tasks, args, "", toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// taskLabelCompletions returns the labels starting with toComplete, prefixed
+// with prefix and described by their group, type and detail.
+func taskLabelCompletions(tasks []config.Task, exclude []string, prefix string, toComplete string) []string {
+	given := make(map[string]bool)
+	for _, label := range exclude {
+		given[label] = true
+	}
+
+	var completions []string
+	for i := range tasks {
+		task := &tasks[i]
+		candidate := prefix + task.Label
+		if given[task.Label] || !strings.HasPrefix(candidate, toComplete) {
+			continue
+		}
+		if summary := taskSummary(task); summary != "" {
+			candidate += "\t" + summary
+		}
+		completions = append(completions, candidate)
+	}
+	return completions
+}
+
+// completeWatchRoute completes the task of a --on <pattern>=<task> route once
+// the pattern has been typed.
+func completeWatchRoute(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	pattern, _, ok := strings.Cut(toComplete, "=")
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+	tasks, err := completionTasks()
+	if err != nil {
+		cobra.CompDebugln(err.Error(), true)
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return taskLabelCompletions(tasks, nil, pattern+"=", toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskGroups completes --group with the standard group kinds and the
+// ones used in tasks.json.
+func completeTaskGroups(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	groups := []string{"build", "test"}
+	if tasks, err := completionTasks(); err == nil {
+		for i := range tasks {
+			groups = appendUnique(groups, tasks[i].GetGroupKind())
+		}
+	}
+	return filterPrefix(groups, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTaskTypes completes --type with the built-in and plugin task types.
+func completeTaskTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	types := append(executor.BuiltinTypes(), executor.PluginTypes()...)
+	return filterPrefix(types, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTemplates completes init --template with the embedded templates.
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return filterPrefix(GetAvailableTemplates(), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func filterPrefix(values []string, prefix string) []string {
+	var matches []string
+	for _, value := range values {
+		if strings.HasPrefix(value, prefix) {
+			matches = append(matches, value)
+		}
+	}
+	return matches
+}
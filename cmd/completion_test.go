@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// setupCompletionWorkspace points --config and --workspace-folder at a
+// workspace with two configured tasks and an npm package in a subfolder.
+func setupCompletionWorkspace(t *testing.T) {
+	origConfigPath, origWorkspaceFolder := configPath, workspaceFolder
+	t.Cleanup(func() {
+		configPath, workspaceFolder = origConfigPath, origWorkspaceFolder
+	})
+
+	dir := t.TempDir()
+	tasksJSON := `{
+  "version": "2.0.0",
+  "tasks": [
+    {"label": "build", "type": "shell", "command": "go build", "group": "build"},
+    {"label": "lint", "type": "shell", "command": "golangci-lint run", "group": {"kind": "check"}, "detail": "Static checks"}
+  ]
+}`
+	if err := os.WriteFile(filepath.Join(dir, "tasks.json"), []byte(tasksJSON), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "web", "package.json"), []byte(`{"scripts": {"dev": "vite"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configPath = filepath.Join(dir, "tasks.json")
+	workspaceFolder = dir
+}
+
+func TestCompleteTaskLabels(t *testing.T) {
+	setupCompletionWorkspace(t)
+
+	tests := []struct {
+		name       string
+		maxArgs    int
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{"all", 1, nil, "", []string{"build\t[build, shell]", "lint\t[check, shell] Static checks", "npm: dev - web\t[npm] vite"}},
+		{"prefix", 1, nil, "npm: d", []string{"npm: dev - web\t[npm] vite"}},
+		{"no match", 1, nil, "x", nil},
+		{"single task given", 1, []string{"build"}, "", nil},
+		{"given tasks are skipped", 0, []string{"build", "npm: dev - web"}, "", []string{"lint\t[check, shell] Static checks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completions, directive := completeTaskLabels(tt.maxArgs)(&cobra.Command{}, tt.args, tt.toComplete)
+			// Labels of detected tasks other than the dev script, like npm
+			// install, depend on the providers and are left out
+			var got []string
+			for _, completion := range completions {
+				if !strings.HasPrefix(completion, "npm: ") || strings.HasPrefix(completion, "npm: dev") {
+					got = append(got, completion)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.expected, "\n") {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+			if directive != cobra.ShellCompDirectiveNoFileComp {
+				t.Errorf("expected file completion to be disabled, got %v", directive)
+			}
+		})
+	}
+}
+
+func TestCompleteWatchRoute(t *testing.T) {
+	setupCompletionWorkspace(t)
+
+	completions, directive := completeWatchRoute(&cobra.Command{}, nil, "*.go")
+	if len(completions) != 0 || directive&cobra.ShellCompDirectiveNoSpace == 0 {
+		t.Errorf("expected nothing to complete before the '=', got %q, %v", completions, directive)
+	}
+
+	completions, _ = completeWatchRoute(&cobra.Command{}, nil, "*.go=l")
+	if strings.Join(completions, "\n") != "*.go=lint\t[check, shell] Static checks" {
+		t.Errorf("expected the task after the pattern, got %q", completions)
+	}
+}
+
+func TestCompleteFlagValues(t *testing.T) {
+	setupCompletionWorkspace(t)
+
+	tests := []struct {
+		name       string
+		complete   completionFunc
+		toComplete string
+		expected   string
+	}{
+		{"groups", completeTaskGroups, "", "build,test,check"},
+		{"groups by prefix", completeTaskGroups, "c", "check"},
+		{"templates", completeTemplates, "", "default,go,node"},
+		{"templates by prefix", completeTemplates, "n", "node"},
+		{"types by prefix", completeTaskTypes, "sh", "shell"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			completions, _ := tt.complete(&cobra.Command{}, nil, tt.toComplete)
+			if got := strings.Join(completions, ","); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var dependentsFlat bool
+
+var dependentsCommand = &cobra.Command{
+	Use:   "dependents <task-name>",
+	Short: "Show the tasks that depend on a task",
+	Long: `Show every task that depends on the given task, directly or through other
+tasks, as a tree. Tasks reached on several paths are expanded once and referred
+back to afterwards.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTaskLabels(1),
+	RunE:              executeDependentsCommand,
+	SilenceUsage:      true,
+}
+
+func executeDependentsCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	tasksFilePath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to find tasks file: %w", err)
+	}
+
+	tasks, err := loadTasksFor(tasksFilePath, workspaceDir, args)
+	if err != nil {
+		return err
+	}
+
+	resolver := executor.NewDependencyResolver(tasks)
+	if dependentsFlat {
+		dependents, err := resolver.Dependents(args[0])
+		if err != nil {
+			return err
+		}
+		for _, label := range dependents {
+			fmt.Println(label)
+		}
+		return nil
+	}
+
+	tree, err := resolver.DependentTree(args[0])
+	if err != nil {
+		return err
+	}
+	if len(tree.Children) == 0 && !quiet {
+		fmt.Printf("No task depends on '%s'\n", args[0])
+		return nil
+	}
+	printTrees(os.Stdout, []*executor.TreeNode{tree})
+	return nil
+}
+
+// printTrees draws task trees with box-drawing characters. Each child shows
+// how its parent edge is ordered: "sequence N" or "parallel".
+func printTrees(w io.Writer, trees []*executor.TreeNode) {
+	for _, tree := range trees {
+		fmt.Fprintln(w, treeNodeText(tree))
+		printTreeChildren(w, tree.Children, "")
+	}
+}
+
+func printTreeChildren(w io.Writer, children []*executor.TreeNode, indent string) {
+	for i, child := range children {
+		branch, nextIndent := "├── ", indent+"│   "
+		if i == len(children)-1 {
+			branch, nextIndent = "└── ", indent+"    "
+		}
+		fmt.Fprintf(w, "%s%s%s\n", indent, branch, treeNodeText(child))
+		printTreeChildren(w, child.Children, nextIndent)
+	}
+}
+
+func treeNodeText(node *executor.TreeNode) string {
+	var notes []string
+	switch node.Order {
+	case "sequence":
+		notes = append(notes, fmt.Sprintf("sequence %d", node.Position))
+	case "parallel":
+		notes = append(notes, "parallel")
+	}
+	switch {
+	case node.Missing:
+		notes = append(notes, "missing")
+	case node.Cycle:
+		notes = append(notes, "cycle")
+	case node.Repeated:
+		notes = append(notes, "see above")
+	}
+
+	if len(notes) == 0 {
+		return node.Label
+	}
+	return fmt.Sprintf("%s (%s)", node.Label, strings.Join(notes, ", "))
+}
+
+func init() {
+	dependentsCommand.Flags().BoolVar(&dependentsFlat, "flat", false, "print only the labels, one per line")
+	dependentsCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	rootCmd.AddCommand(dependentsCommand)
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+)
+
+func TestPrintTrees(t *testing.T) {
+	trees := []*executor.TreeNode{
+		{Label: "deploy", Children: []*executor.TreeNode{
+			{Label: "build", Order: "sequence", Position: 1, Children: []*executor.TreeNode{
+				{Label: "codegen", Order: "parallel"},
+				{Label: "lint", Order: "parallel", Missing: true},
+			}},
+			{Label: "codegen", Order: "sequence", Position: 2, Repeated: true},
+		}},
+		{Label: "a", Children: []*executor.TreeNode{
+			{Label: "a", Order: "parallel", Cycle: true},
+		}},
+	}
+
+	var out bytes.Buffer
+	printTrees(&out, trees)
+
+	expected := `deploy
+├── build (sequence 1)
+│   ├── codegen (parallel)
+│   └── lint (parallel, missing)
+└── codegen (sequence 2, see above)
+a
+└── a (parallel, cycle)
+`
+	if out.String() != expected {
+		t.Errorf("unexpected tree output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openEventsFD returns file descriptor 3 if the caller opened it for writing.
+// The Go runtime may hold a descriptor of its own there, but not a writable one.
+func openEventsFD() (*os.File, error) {
+	flags, _, errno := syscall.Syscall(syscall.SYS_FCNTL, 3, syscall.F_GETFL, 0)
+	if errno != 0 || flags&syscall.O_ACCMODE == syscall.O_RDONLY {
+		return nil, fmt.Errorf("file descriptor 3 is not open for writing; redirect it (3>events.jsonl) or use --events-output")
+	}
+	return os.NewFile(3, "fd3"), nil
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// openEventsFD fails on Windows, which has no numbered file descriptors to
+// inherit.
+func openEventsFD() (*os.File, error) {
+	return nil, fmt.Errorf("writing events to file descriptor 3 is not supported on Windows; use --events-output")
+}
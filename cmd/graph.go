@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+var graphFormat string
+
+var graphCommand = &cobra.Command{
+	Use:   "graph [task-name]",
+	Short: "Print the task dependency graph",
+	Long: `Print the dependency graph of all tasks, or of the given task and its
+dependencies, as Graphviz DOT, Mermaid or JSON. Edges point from a task to its
+dependencies; dependencies run in sequence are numbered, parallel ones dashed.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeTaskLabels(1),
+	RunE:              executeGraphCommand,
+	SilenceUsage:      true,
+}
+
+func executeGraphCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+
+	tasksFilePath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to find tasks file: %w", err)
+	}
+
+	tasks, err := loadTasksFor(tasksFilePath, workspaceDir, args)
+	if err != nil {
+		return err
+	}
+
+	root := ""
+	if len(args) > 0 {
+		root = args[0]
+	}
+	graph, err := executor.NewDependencyResolver(tasks).Graph(root)
+	if err != nil {
+		return err
+	}
+
+	switch graphFormat {
+	case "dot":
+		writeDotGraph(os.Stdout, graph)
+	case "mermaid":
+		writeMermaidGraph(os.Stdout, graph)
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	default:
+		return fmt.Errorf("unknown graph format '%s', supported formats: dot, mermaid, json", graphFormat)
+	}
+	return nil
+}
+
+// nodeDescription summarizes a node's type and group, e.g. "shell, build".
+func nodeDescription(node executor.GraphNode) string {
+	if node.Missing {
+		return "missing"
+	}
+	var parts []string
+	for _, part := range []string{node.Type, node.Group} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// edgeLabel is "1", "2", ... for dependencies in sequence and "parallel"
+// otherwise.
+func edgeLabel(edge executor.GraphEdge) string {
+	if edge.Order == "sequence" {
+		return fmt.Sprintf("%d", edge.Position)
+	}
+	return "parallel"
+}
+
+func writeDotGraph(w io.Writer, graph *executor.Graph) {
+	fmt.Fprintln(w, "digraph tasks {")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, node := range graph.Nodes {
+		label := node.Label
+		if description := nodeDescription(node); description != "" {
+			label += "\n" + description
+		}
+		attributes := fmt.Sprintf("label=%s", dotQuote(label))
+		if node.Missing {
+			attributes += ", style=dashed, color=red"
+		}
+		fmt.Fprintf(w, "  %s [%s];\n", dotQuote(node.Label), attributes)
+	}
+	for _, node := range graph.Nodes {
+		for _, edge := range node.DependsOn {
+			attributes := fmt.Sprintf("label=%s", dotQuote(edgeLabel(edge)))
+			if edge.Order != "sequence" {
+				attributes += ", style=dashed"
+			}
+			fmt.Fprintf(w, "  %s -> %s [%s];\n", dotQuote(node.Label), dotQuote(edge.Label), attributes)
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func writeMermaidGraph(w io.Writer, graph *executor.Graph) {
+	// Labels may contain anything, so nodes get generated ids
+	ids := make(map[string]string)
+	for i, node := range graph.Nodes {
+		ids[node.Label] = fmt.Sprintf("t%d", i)
+	}
+
+	fmt.Fprintln(w, "flowchart TD")
+	for _, node := range graph.Nodes {
+		label := mermaidEscape(node.Label)
+		if description := nodeDescription(node); description != "" {
+			label += "<br/><small>" + mermaidEscape(description) + "</small>"
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[node.Label], label)
+	}
+	for _, node := range graph.Nodes {
+		for _, edge := range node.DependsOn {
+			arrow := "-.->"
+			if edge.Order == "sequence" {
+				arrow = "-->"
+			}
+			fmt.Fprintf(w, "  %s %s|%s| %s\n", ids[node.Label], arrow, edgeLabel(edge), ids[edge.Label])
+		}
+	}
+	for _, node := range graph.Nodes {
+		if node.Missing {
+			fmt.Fprintf(w, "  style %s stroke:#f00,stroke-dasharray:4\n", ids[node.Label])
+		}
+	}
+}
+
+// mermaidEscape replaces the characters that end a quoted Mermaid label.
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+func init() {
+	graphCommand.Flags().StringVar(&graphFormat, "format", "dot", "output format: dot, mermaid or json")
+	graphCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	rootCmd.AddCommand(graphCommand)
+}
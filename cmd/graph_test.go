@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+)
+
+func testGraph() *executor.Graph {
+	return &executor.Graph{Nodes: []executor.GraphNode{
+		{Label: "build", Type: "shell", Group: "build", DependsOn: []executor.GraphEdge{
+			{Label: "npm: install", Order: "sequence", Position: 1},
+			{Label: `say "hi"`, Order: "parallel"},
+		}},
+		{Label: "npm: install", Type: "npm"},
+		{Label: `say "hi"`, Missing: true},
+	}}
+}
+
+func TestWriteDotGraph(t *testing.T) {
+	var out bytes.Buffer
+	writeDotGraph(&out, testGraph())
+
+	expected := `digraph tasks {
+  node [shape=box];
+  "build" [label="build\nshell, build"];
+  "npm: install" [label="npm: install\nnpm"];
+  "say \"hi\"" [label="say \"hi\"\nmissing", style=dashed, color=red];
+  "build" -> "npm: install" [label="1"];
+  "build" -> "say \"hi\"" [label="parallel", style=dashed];
+}
+`
+	if out.String() != expected {
+		t.Errorf("unexpected DOT output:\n%s", out.String())
+	}
+}
+
+func TestWriteMermaidGraph(t *testing.T) {
+	var out bytes.Buffer
+	writeMermaidGraph(&out, testGraph())
+
+	expected := `flowchart TD
+  t0["build<br/><small>shell, build</small>"]
+  t1["npm: install<br/><small>npm</small>"]
+  t2["say #quot;hi#quot;<br/><small>missing</small>"]
+  t0 -->|1| t1
+  t0 -.->|parallel| t2
+  style t2 stroke:#f00,stroke-dasharray:4
+`
+	if out.String() != expected {
+		t.Errorf("unexpected Mermaid output:\n%s", out.String())
+	}
+}
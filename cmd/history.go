@@ -0,0 +1,307 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/history"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var historyTask string
+var historyFailed bool
+var historySince string
+var historyLimit int
+var historyAllWorkspaces bool
+
+var historyCommand = &cobra.Command{
+	Use:   "history",
+	Short: "List past runs",
+	Long: `List the runs recorded by the run command, newest first. Runs are kept in
+$XDG_STATE_HOME/tasks-json-cli/history.jsonl (~/.local/state by default).`,
+	Args:         cobra.NoArgs,
+	RunE:         executeHistoryCommand,
+	SilenceUsage: true,
+}
+
+var rerunCommand = &cobra.Command{
+	Use:   "rerun",
+	Short: "Run the last run task again",
+	Long: `Run the task of the last recorded run in this workspace again, with the same
+tasks file, ${file} and flags.`,
+	Args:         cobra.NoArgs,
+	RunE:         executeRerunCommand,
+	SilenceUsage: true,
+}
+
+var statsCommand = &cobra.Command{
+	Use:   "stats [task-name...]",
+	Short: "Show duration and failure rate per task",
+	Long: `Show how often each task ran, how often it failed and how long it took on
+average, from the recorded runs. Dependencies count as runs of their own.`,
+	Args:         cobra.ArbitraryArgs,
+	RunE:         executeStatsCommand,
+	SilenceUsage: true,
+}
+
+// historyStore returns the store runs are recorded in.
+func historyStore() (*history.Store, error) {
+	path, err := history.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate history: %w", err)
+	}
+	return history.NewStore(path), nil
+}
+
+// recordRun adds a run of the run command to the history. Failing to do so
+// does not fail the run.
+func recordRun(cmd *cobra.Command, entry history.Entry, runErr error) {
+	if abs, err := filepath.Abs(entry.Workspace); err == nil {
+		entry.Workspace = abs
+	}
+	if abs, err := filepath.Abs(entry.TasksFile); err == nil {
+		entry.TasksFile = abs
+	}
+	entry.Flags = make(map[string]string)
+	local := cmd.LocalFlags()
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		// Global flags like --quiet are not replayed, the workspace folder is
+		// recorded in the entry itself
+		if local.Lookup(flag.Name) != nil && flag.Name != "workspace-folder" {
+			entry.Flags[flag.Name] = flag.Value.String()
+		}
+	})
+	if runErr != nil {
+		entry.ExitCode = 1
+		for _, result := range entry.Tasks {
+			if result.Failed() && result.ExitCode > 0 {
+				entry.ExitCode = result.ExitCode
+			}
+		}
+	}
+
+	store, err := historyStore()
+	if err == nil {
+		err = store.Append(entry)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record run history: %v\n", err)
+	}
+}
+
+// currentWorkspace returns the absolute workspace folder that history is
+// filtered by.
+func currentWorkspace() (string, error) {
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(workspaceDir)
+}
+
+// historyFilter builds the filter shared by history and stats.
+func historyFilter() (history.Filter, error) {
+	var filter history.Filter
+	if !historyAllWorkspaces {
+		workspaceDir, err := currentWorkspace()
+		if err != nil {
+			return filter, err
+		}
+		filter.Workspace = workspaceDir
+	}
+	if historySince != "" {
+		since, err := parseSince(historySince, time.Now())
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = since
+	}
+	return filter, nil
+}
+
+// parseSince accepts a duration before now, like "24h", or a date.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since '%s', expected a duration like 24h or a date like 2006-01-02", value)
+}
+
+func executeHistoryCommand(cmd *cobra.Command, args []string) error {
+	store, err := historyStore()
+	if err != nil {
+		return err
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	filter, err := historyFilter()
+	if err != nil {
+		return err
+	}
+	filter.Task = historyTask
+	filter.Failed = historyFailed
+
+	entries = filter.Select(entries)
+	if len(entries) == 0 {
+		if !quiet {
+			fmt.Println("No runs recorded")
+		}
+		return nil
+	}
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[len(entries)-historyLimit:]
+	}
+	printHistory(os.Stdout, entries, historyAllWorkspaces)
+	return nil
+}
+
+// printHistory prints runs newest first.
+func printHistory(w io.Writer, entries []history.Entry, showWorkspace bool) {
+	fmt.Fprintf(w, "%-19s  %-20s %10s %5s  %s\n", "START", "TASK", "DURATION", "EXIT", "FLAGS")
+	fmt.Fprintln(w, strings.Repeat("-", 70))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		line := fmt.Sprintf("%-19s  %-20s %10s %5d  %s", entry.Start.Local().Format("2006-01-02 15:04:05"), entry.Task, entry.Duration.Round(time.Millisecond), entry.ExitCode, formatFlags(entry.Flags))
+		if showWorkspace {
+			line += "  " + entry.Workspace
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+}
+
+// formatFlags renders recorded flags as command-line arguments, sorted by name.
+func formatFlags(flags map[string]string) string {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		value := flags[name]
+		switch {
+		case value == "true":
+			args = append(args, "--"+name)
+		case strings.ContainsAny(value, " \t\"'") || value == "":
+			args = append(args, fmt.Sprintf("--%s=%q", name, value))
+		default:
+			args = append(args, fmt.Sprintf("--%s=%s", name, value))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+func executeRerunCommand(cmd *cobra.Command, args []string) error {
+	workspaceDir, err := currentWorkspace()
+	if err != nil {
+		return err
+	}
+	store, err := historyStore()
+	if err != nil {
+		return err
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	entries = history.Filter{Workspace: workspaceDir}.Select(entries)
+	if len(entries) == 0 {
+		return fmt.Errorf("no run recorded in %s", workspaceDir)
+	}
+	last := entries[len(entries)-1]
+
+	workspaceFolder = last.Workspace
+	configPath = last.TasksFile
+	for name, value := range last.Flags {
+		if err := runCommand.Flags().Set(name, value); err != nil {
+			return fmt.Errorf("failed to restore flag --%s: %w", name, err)
+		}
+	}
+
+	if !quiet {
+		fmt.Println(strings.TrimSpace(fmt.Sprintf("Rerunning: run %s %s", last.Task, formatFlags(last.Flags))))
+	}
+	return executeRunCommand(runCommand, []string{last.Task})
+}
+
+func executeStatsCommand(cmd *cobra.Command, args []string) error {
+	store, err := historyStore()
+	if err != nil {
+		return err
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		return err
+	}
+	filter, err := historyFilter()
+	if err != nil {
+		return err
+	}
+
+	stats := history.Stats(filter.Select(entries))
+	if len(args) > 0 {
+		wanted := make(map[string]bool)
+		for _, label := range args {
+			wanted[label] = true
+		}
+		var selected []history.TaskStats
+		for _, s := range stats {
+			if wanted[s.Label] {
+				selected = append(selected, s)
+			}
+		}
+		stats = selected
+	}
+	if len(stats) == 0 {
+		if !quiet {
+			fmt.Println("No runs recorded")
+		}
+		return nil
+	}
+	printStats(os.Stdout, stats)
+	return nil
+}
+
+func printStats(w io.Writer, stats []history.TaskStats) {
+	fmt.Fprintf(w, "%-20s %5s %7s %8s %12s  %s\n", "TASK", "RUNS", "FAILED", "SKIPPED", "AVG DURATION", "LAST RUN")
+	fmt.Fprintln(w, strings.Repeat("-", 78))
+	for _, s := range stats {
+		failed := fmt.Sprintf("%.0f%%", s.FailureRate()*100)
+		average := "-"
+		if s.Runs > s.Skipped {
+			average = s.Average().Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%-20s %5d %7s %8d %12s  %s\n", s.Label, s.Runs, failed, s.Skipped, average, s.LastRun.Local().Format("2006-01-02 15:04:05"))
+	}
+}
+
+func init() {
+	historyCommand.Flags().StringVar(&historyTask, "task", "", "only runs of this task, or that ran it as a dependency")
+	historyCommand.Flags().BoolVar(&historyFailed, "failed", false, "only failed runs")
+	historyCommand.Flags().StringVar(&historySince, "since", "", "only runs since a duration ago (24h) or a date (2006-01-02)")
+	historyCommand.Flags().IntVarP(&historyLimit, "limit", "n", 20, "show at most this many runs, 0 for all")
+	historyCommand.Flags().BoolVar(&historyAllWorkspaces, "all", false, "include runs in other workspaces")
+	historyCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+
+	statsCommand.Flags().StringVar(&historySince, "since", "", "only runs since a duration ago (24h) or a date (2006-01-02)")
+	statsCommand.Flags().BoolVar(&historyAllWorkspaces, "all", false, "include runs in other workspaces")
+	statsCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+
+	rerunCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+
+	rootCmd.AddCommand(historyCommand)
+	rootCmd.AddCommand(rerunCommand)
+	rootCmd.AddCommand(statsCommand)
+}
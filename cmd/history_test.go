@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// Runs made by the tests must not end up in the user's history
+	stateDir, err := os.MkdirTemp("", "tasks-json-cli-state")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_STATE_HOME", stateDir)
+	code := m.Run()
+	os.RemoveAll(stateDir)
+	os.Exit(code)
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{"24h", now.Add(-24 * time.Hour), false},
+		{"90m", now.Add(-90 * time.Minute), false},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local), false},
+		{"last week", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSince(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFormatFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		flags    map[string]string
+		expected string
+	}{
+		{"none", nil, ""},
+		{"bool", map[string]string{"force": "true"}, "--force"},
+		{"sorted", map[string]string{"no-cache": "true", "file": "src/main.go"}, "--file=src/main.go --no-cache"},
+		{"quoted", map[string]string{"file": "my file.go"}, `--file="my file.go"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatFlags(tt.flags); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
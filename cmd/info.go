@@ -7,20 +7,25 @@ import (
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
 	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
 	"github.com/spf13/cobra"
 )
 
 var infoCmd = &cobra.Command{
 	Use:   "info <task-name>",
 	Short: "Show task details",
-	Long:  `Show detailed information about a specific task.`,
+	Long:  `Show detailed information about a specific task, or about every task
+matching a glob like 'test:*'.`,
 	Args:  cobra.ExactArgs(1),
+	ValidArgsFunction: completeTaskLabels(1),
 	RunE:  runInfoCommand,
 	SilenceUsage: true,
 }
 
 func init() {
 	rootCmd.AddCommand(infoCmd)
+	infoCmd.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	infoCmd.Flags().BoolVar(&looseMatch, "loose", false, "match task names case-insensitively or by a unique prefix")
 }
 
 func runInfoCommand(cmd *cobra.Command, args []string) error {
@@ -35,25 +40,26 @@ func runInfoCommand(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Loading tasks from: %s\n", tasksPath)
 	}
 
-	tasks, err := config.LoadTasks(tasksPath)
+	workspaceDir, err := resolveWorkspaceDir()
 	if err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
+		return err
 	}
 
-	task := findTaskByName(tasks, taskName)
-	if task == nil {
-		return fmt.Errorf("task '%s' not found", taskName)
+	tasks, err := loadTasksFor(tasksPath, workspaceDir, []string{taskName})
+	if err != nil {
+		return err
 	}
 
-	printTaskInfo(task, tasksPath)
-	return nil
-}
+	matches, err := lookupTasks(tasks, taskName)
+	if err != nil {
+		return err
+	}
 
-func findTaskByName(tasks []config.Task, name string) *config.Task {
-	for _, task := range tasks {
-		if task.Label == name {
-			return &task
+	for i, task := range matches {
+		if i > 0 && !quiet {
+			fmt.Println()
 		}
+		printTaskInfo(task, tasksPath)
 	}
 	return nil
 }
@@ -72,6 +78,10 @@ func printTaskInfo(task *config.Task, tasksPath string) {
 	fmt.Printf("Type:     %s\n", task.Type)
 	fmt.Printf("Command:  %s\n", task.Command)
 	
+	if task.Detail != "" {
+		fmt.Printf("Detail:   %s\n", task.Detail)
+	}
+	
 	if len(task.Args) > 0 {
 		fmt.Printf("Args:     %s\n", strings.Join(task.Args, " "))
 	}
@@ -81,6 +91,17 @@ func printTaskInfo(task *config.Task, tasksPath string) {
 		fmt.Printf("Group:    %s\n", group)
 	}
 
+	if executor.IsBackgroundTask(task) {
+		fmt.Printf("Background: yes\n")
+	}
+
+	if task.Script != "" {
+		fmt.Printf("Script:   %s\n", task.Script)
+	}
+	if task.Path != "" {
+		fmt.Printf("Path:     %s\n", task.Path)
+	}
+
 
 	// Options
 	if task.Options != nil {
@@ -122,6 +143,25 @@ func printTaskInfo(task *config.Task, tasksPath string) {
 		fmt.Printf("Problem Matcher: %v\n", task.ProblemMatcher)
 	}
 
+	// Inputs, outputs and caching
+	if len(task.Inputs) > 0 || len(task.Outputs) > 0 || task.Cache {
+		fmt.Println()
+	}
+	if len(task.Inputs) > 0 {
+		fmt.Printf("Inputs: %s\n", strings.Join(task.Inputs, ", "))
+	}
+	if len(task.Outputs) > 0 {
+		fmt.Printf("Outputs: %s\n", strings.Join(task.Outputs, ", "))
+	}
+	if task.Cache {
+		fmt.Println("Cached: yes")
+	}
+
+	// Watch configuration
+	if task.Watch != nil {
+		printWatchConfig(task.Watch)
+	}
+
 	// Verbose information
 	if verbose {
 		fmt.Println()
@@ -131,6 +171,36 @@ func printTaskInfo(task *config.Task, tasksPath string) {
 	}
 }
 
+func printWatchConfig(watch *config.WatchConfig) {
+	fmt.Println()
+	fmt.Println("Watch:")
+	if len(watch.Paths) > 0 {
+		fmt.Printf("  Paths:   %s\n", strings.Join(watch.Paths, ", "))
+	}
+	if len(watch.Include) > 0 {
+		fmt.Printf("  Include: %s\n", strings.Join(watch.Include, ", "))
+	}
+	if len(watch.Exclude) > 0 {
+		fmt.Printf("  Exclude: %s\n", strings.Join(watch.Exclude, ", "))
+	}
+	if watch.Delay != nil {
+		if delay, err := watch.GetDelay(); err == nil {
+			fmt.Printf("  Delay:   %s\n", delay)
+		} else {
+			fmt.Printf("  Delay:   %v\n", watch.Delay)
+		}
+	}
+	if watch.Restart != nil {
+		fmt.Printf("  Restart: %v\n", *watch.Restart)
+	}
+	if watch.Server != nil {
+		fmt.Printf("  Server:  %v\n", *watch.Server)
+	}
+	if watch.Poll != nil {
+		fmt.Printf("  Poll:    %v\n", *watch.Poll)
+	}
+}
+
 func printTaskInfoQuiet(task *config.Task) {
 	fmt.Printf("%s\t%s\t%s", task.Label, task.Type, task.Command)
 	if len(task.Args) > 0 {
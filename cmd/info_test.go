@@ -91,47 +91,6 @@ func TestRunInfoCommand(t *testing.T) {
 	}
 }
 
-func TestFindTaskByName(t *testing.T) {
-	tasks := []config.Task{
-		{Label: "build", Type: "shell", Command: "go build"},
-		{Label: "test", Type: "shell", Command: "go test"},
-	}
-
-	tests := []struct {
-		name     string
-		taskName string
-		expected *config.Task
-	}{
-		{
-			name:     "existing task",
-			taskName: "build",
-			expected: &tasks[0],
-		},
-		{
-			name:     "non-existent task",
-			taskName: "nonexistent",
-			expected: nil,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := findTaskByName(tasks, tt.taskName)
-			if tt.expected == nil {
-				if result != nil {
-					t.Errorf("expected nil, got %v", result)
-				}
-			} else {
-				if result == nil {
-					t.Errorf("expected task, got nil")
-				} else if result.Label != tt.expected.Label {
-					t.Errorf("expected label %s, got %s", tt.expected.Label, result.Label)
-				}
-			}
-		})
-	}
-}
-
 func TestPrintTaskInfo(t *testing.T) {
 	// Save original values
 	origVerbose := verbose
@@ -154,6 +113,10 @@ func TestPrintTaskInfo(t *testing.T) {
 			},
 		},
 		DependsOn: []string{"other-task"},
+		Watch: &config.WatchConfig{
+			Include: []string{"src/**/*.go"},
+			Delay:   "300ms",
+		},
 	}
 
 	tests := []struct {
@@ -175,6 +138,9 @@ func TestPrintTaskInfo(t *testing.T) {
 				"Working Directory: /tmp",
 				"TEST_VAR=test_value",
 				"Depends On: other-task",
+				"Watch:",
+				"Include: src/**/*.go",
+				"Delay:   300ms",
 			},
 		},
 		{
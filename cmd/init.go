@@ -33,6 +33,7 @@ func init() {
 	initCmd.Flags().StringVarP(&templateName, "template", "t", "default", "template to use (default, go, node)")
 	initCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "overwrite existing file")
 	initCmd.Flags().StringVarP(&outputPath, "output", "o", "", "output path (default: .vscode/tasks.json)")
+	_ = initCmd.RegisterFlagCompletionFunc("template", completeTemplates)
 }
 
 func runInitCommand(cmd *cobra.Command, args []string) error {
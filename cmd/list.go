@@ -7,12 +7,15 @@ import (
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
 	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
 	"github.com/spf13/cobra"
 )
 
 var (
 	groupFilter string
 	typeFilter  string
+	listTypes   bool
+	listTree    bool
 )
 
 var listCmd = &cobra.Command{
@@ -27,9 +30,19 @@ func init() {
 	rootCmd.AddCommand(listCmd)
 	listCmd.Flags().StringVar(&groupFilter, "group", "", "filter by group (build, test)")
 	listCmd.Flags().StringVar(&typeFilter, "type", "", "filter by type (shell, process)")
+	listCmd.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	listCmd.Flags().BoolVar(&listTypes, "types", false, "list supported task types, including plugin types")
+	listCmd.Flags().BoolVar(&listTree, "tree", false, "show tasks as a tree of their dependencies")
+	_ = listCmd.RegisterFlagCompletionFunc("group", completeTaskGroups)
+	_ = listCmd.RegisterFlagCompletionFunc("type", completeTaskTypes)
 }
 
 func runListCommand(cmd *cobra.Command, args []string) error {
+	if listTypes {
+		printTaskTypes()
+		return nil
+	}
+
 	tasksPath, err := discovery.FindTasksFile(configPath)
 	if err != nil {
 		return fmt.Errorf("failed to find tasks file: %w", err)
@@ -39,9 +52,14 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		fmt.Fprintf(os.Stderr, "Loading tasks from: %s\n", tasksPath)
 	}
 
-	tasks, err := config.LoadTasks(tasksPath)
+	workspaceDir, err := resolveWorkspaceDir()
 	if err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
+		return err
+	}
+
+	tasks, err := loadAllTasks(tasksPath, workspaceDir)
+	if err != nil {
+		return err
 	}
 
 	filteredTasks := filterTasks(tasks, groupFilter, typeFilter)
@@ -53,10 +71,32 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if listTree {
+		return printTaskTree(tasks, filteredTasks)
+	}
+
 	printTasks(filteredTasks)
 	return nil
 }
 
+// printTaskTree prints the dependency trees of the tasks no other task
+// depends on, or of the filtered tasks if a filter is given.
+func printTaskTree(tasks []config.Task, filteredTasks []config.Task) error {
+	var roots []string
+	if groupFilter != "" || typeFilter != "" {
+		for _, task := range filteredTasks {
+			roots = append(roots, task.Label)
+		}
+	}
+
+	trees, err := executor.NewDependencyResolver(tasks).DependencyTrees(roots...)
+	if err != nil {
+		return err
+	}
+	printTrees(os.Stdout, trees)
+	return nil
+}
+
 func filterTasks(tasks []config.Task, groupFilter, typeFilter string) []config.Task {
 	var filtered []config.Task
 
@@ -94,6 +134,9 @@ func printTasks(tasks []config.Task) {
 		if len(task.Args) > 0 {
 			command += " " + strings.Join(task.Args, " ")
 		}
+		if command == "" {
+			command = task.Detail
+		}
 
 		if len(command) > 30 {
 			command = command[:27] + "..."
@@ -101,4 +144,23 @@ func printTasks(tasks []config.Task) {
 
 		fmt.Printf("%-20s %-8s %-8s %s\n", task.Label, task.Type, group, command)
 	}
-}
\ No newline at end of file
+}
+
+func printTaskTypes() {
+	for _, taskType := range executor.BuiltinTypes() {
+		if quiet {
+			fmt.Println(taskType)
+		} else {
+			fmt.Printf("%-12s %s\n", taskType, "built-in")
+		}
+	}
+
+	for _, taskType := range executor.PluginTypes() {
+		if quiet {
+			fmt.Println(taskType)
+			continue
+		}
+		pluginPath, _ := executor.FindPlugin(taskType)
+		fmt.Printf("%-12s %s\n", taskType, pluginPath)
+	}
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// looseMatch lets a task name match a label case-insensitively or as a
+// unique prefix when no label matches it exactly.
+var looseMatch bool
+
+// maxSuggestions is how many similar labels a "not found" error offers.
+const maxSuggestions = 3
+
+// lookupTasks finds the tasks a name given on the command line selects. A
+// label matching exactly wins; otherwise a name containing *, ? or [ is a glob
+// selecting every matching task in tasks.json order, and with --loose a name
+// may differ in case or be the prefix of a single label. Errors for unknown
+// names suggest the closest labels.
+func lookupTasks(tasks []config.Task, name string) ([]*config.Task, error) {
+	for i := range tasks {
+		if tasks[i].Label == name {
+			return []*config.Task{&tasks[i]}, nil
+		}
+	}
+
+	if strings.ContainsAny(name, "*?[") {
+		return globTasks(tasks, name)
+	}
+
+	if looseMatch {
+		if matches := matchTasks(tasks, name, strings.EqualFold); len(matches) > 0 {
+			return singleMatch(name, matches)
+		}
+		hasPrefix := func(label, name string) bool {
+			return strings.HasPrefix(strings.ToLower(label), strings.ToLower(name))
+		}
+		if matches := matchTasks(tasks, name, hasPrefix); len(matches) > 0 {
+			return singleMatch(name, matches)
+		}
+	}
+
+	return nil, taskNotFoundError(tasks, name)
+}
+
+// lookupTask is lookupTasks for commands that take a single task.
+func lookupTask(tasks []config.Task, name string) (*config.Task, error) {
+	matches, err := lookupTasks(tasks, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := singleMatch(name, matches); err != nil {
+		return nil, err
+	}
+	return matches[0], nil
+}
+
+// globTasks returns the tasks whose labels match pattern. Unlike in file
+// paths, * and ? also match slashes, which appear in the labels of tasks
+// detected in subfolders.
+func globTasks(tasks []config.Task, pattern string) ([]*config.Task, error) {
+	normalize := func(s string) string {
+		s = strings.ReplaceAll(s, "/", "\x00")
+		if looseMatch {
+			s = strings.ToLower(s)
+		}
+		return s
+	}
+	if _, err := path.Match(normalize(pattern), ""); err != nil {
+		return nil, fmt.Errorf("invalid task pattern '%s': %w", pattern, err)
+	}
+
+	matches := matchTasks(tasks, pattern, func(label, pattern string) bool {
+		matched, _ := path.Match(normalize(pattern), normalize(label))
+		return matched
+	})
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no task matches '%s'", pattern)
+	}
+	return matches, nil
+}
+
+func matchTasks(tasks []config.Task, name string, match func(label, name string) bool) []*config.Task {
+	var matches []*config.Task
+	for i := range tasks {
+		if match(tasks[i].Label, name) {
+			matches = append(matches, &tasks[i])
+		}
+	}
+	return matches
+}
+
+// singleMatch fails if a name that should select one task matches several.
+func singleMatch(name string, matches []*config.Task) ([]*config.Task, error) {
+	if len(matches) == 1 {
+		return matches, nil
+	}
+	labels := make([]string, len(matches))
+	for i, task := range matches {
+		labels[i] = task.Label
+	}
+	return nil, fmt.Errorf("task name '%s' is ambiguous, it matches %s", name, quoteList(labels, "and"))
+}
+
+// taskNotFoundError reports an unknown task name together with the labels
+// closest to it.
+func taskNotFoundError(tasks []config.Task, name string) error {
+	suggestions := suggestTaskLabels(tasks, name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("task '%s' not found", name)
+	}
+	return fmt.Errorf("task '%s' not found, did you mean %s?", name, quoteList(suggestions, "or"))
+}
+
+// suggestTaskLabels returns up to maxSuggestions labels within a small edit
+// distance of name, or starting with it, closest first. Case is ignored.
+func suggestTaskLabels(tasks []config.Task, name string) []string {
+	type candidate struct {
+		label    string
+		distance int
+	}
+
+	lowerName := strings.ToLower(name)
+	maxDistance := len([]rune(name)) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+
+	var candidates []candidate
+	for _, task := range tasks {
+		label := strings.ToLower(task.Label)
+		distance := editDistance(lowerName, label)
+		if strings.HasPrefix(label, lowerName) && lowerName != "" {
+			// A prefix is as close as a typo, however long the rest is
+			distance = min(distance, 1)
+		}
+		if distance <= maxDistance {
+			candidates = append(candidates, candidate{task.Label, distance})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var labels []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		labels = append(labels, candidates[i].label)
+	}
+	return labels
+}
+
+// editDistance is the Levenshtein distance between a and b, counted in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// quoteList renders 'a', 'b' <conjunction> 'c'.
+func quoteList(values []string, conjunction string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " " + conjunction + " " + quoted[len(quoted)-1]
+}
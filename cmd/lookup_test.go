@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func lookupTestTasks() []config.Task {
+	return []config.Task{
+		{Label: "build"},
+		{Label: "Build:Docs"},
+		{Label: "test:unit"},
+		{Label: "test:e2e"},
+		{Label: "lint"},
+		{Label: "npm: test - packages/web"},
+	}
+}
+
+func TestLookupTasks(t *testing.T) {
+	origLoose := looseMatch
+	defer func() { looseMatch = origLoose }()
+
+	tests := []struct {
+		name        string
+		query       string
+		loose       bool
+		expected    string
+		expectedErr string
+	}{
+		{"exact", "build", false, "build", ""},
+		{"exact wins over prefix", "build", true, "build", ""},
+		{"glob", "test:*", false, "test:unit,test:e2e", ""},
+		{"glob across folders", "npm: test - packages/*", false, "npm: test - packages/web", ""},
+		{"glob without matches", "deploy:*", false, "", "no task matches 'deploy:*'"},
+		{"invalid glob", "test:[", false, "", "invalid task pattern 'test:['"},
+		{"case differs", "LINT", false, "", "task 'LINT' not found, did you mean 'lint'?"},
+		{"case differs, loose", "LINT", true, "lint", ""},
+		{"unique prefix, loose", "test:e", true, "test:e2e", ""},
+		{"ambiguous prefix, loose", "test:", true, "", "task name 'test:' is ambiguous, it matches 'test:unit' and 'test:e2e'"},
+		{"case-insensitive glob, loose", "build:*", true, "Build:Docs", ""},
+		{"typo", "biuld", false, "", "task 'biuld' not found, did you mean 'build'?"},
+		{"several suggestions", "test", false, "", "task 'test' not found, did you mean 'test:unit' or 'test:e2e'?"},
+		{"no suggestions", "deploy", false, "", "task 'deploy' not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			looseMatch = tt.loose
+			matches, err := lookupTasks(lookupTestTasks(), tt.query)
+			if tt.expectedErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.expectedErr) {
+					t.Errorf("expected error %q, got %v", tt.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := taskLabels(matches); got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLookupTask_Glob(t *testing.T) {
+	if _, err := lookupTask(lookupTestTasks(), "test:*"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("expected a glob matching several tasks to be ambiguous, got %v", err)
+	}
+	task, err := lookupTask(lookupTestTasks(), "lin?")
+	if err != nil || task.Label != "lint" {
+		t.Errorf("expected lint, got %v, %v", task, err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"build", "build", 0},
+		{"biuld", "build", 2},
+		{"tst", "test", 1},
+		{"", "lint", 4},
+		{"über", "uber", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
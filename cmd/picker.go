@@ -0,0 +1,461 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/garaemon/tasks-json-cli/internal/history"
+	"golang.org/x/term"
+)
+
+// pickerHeight is how many tasks the picker shows at once.
+const pickerHeight = 10
+
+var errNoTaskSelected = errors.New("no task selected")
+
+// canPickTask reports whether a task can be picked interactively, i.e. both
+// stdin and stdout are terminals.
+func canPickTask() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// pickTask lets the user choose one of tasks, most recently run first, with a
+// fuzzy-search picker, or with a numbered menu if the terminal does not
+// support raw mode. verb names the action in the prompt.
+func pickTask(tasks []config.Task, workspaceDir string, verb string) (*config.Task, error) {
+	if len(tasks) == 0 {
+		return nil, fmt.Errorf("no tasks found")
+	}
+	ordered := orderByRecentUse(tasks, recentTasks(workspaceDir))
+
+	fd := int(os.Stdin.Fd())
+	if os.Getenv("TERM") != "dumb" {
+		if state, err := term.MakeRaw(fd); err == nil {
+			defer func() { _ = term.Restore(fd, state) }()
+			width, _, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || width <= 0 {
+				width = 80
+			}
+			p := newPicker(ordered, verb, func(task *config.Task) string {
+				return previewCommand(task, workspaceDir)
+			})
+			return p.run(os.Stdin, os.Stdout, width)
+		}
+	}
+	return numberedMenu(os.Stdin, os.Stdout, ordered, verb)
+}
+
+// recentTasks returns the labels of the tasks run in the workspace, most
+// recent first.
+func recentTasks(workspaceDir string) []string {
+	workspaceDir, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return nil
+	}
+	store, err := historyStore()
+	if err != nil {
+		return nil
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var labels []string
+	entries = history.Filter{Workspace: workspaceDir}.Select(entries)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if label := entries[i].Task; !seen[label] {
+			seen[label] = true
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// orderByRecentUse puts the recently used tasks first, in the order of recent,
+// followed by the others in tasks.json order.
+func orderByRecentUse(tasks []config.Task, recent []string) []*config.Task {
+	rank := make(map[string]int)
+	for i, label := range recent {
+		rank[label] = i + 1
+	}
+
+	ordered := make([]*config.Task, len(tasks))
+	for i := range tasks {
+		ordered[i] = &tasks[i]
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, rj := rank[ordered[i].Label], rank[ordered[j].Label]
+		if ri == 0 || rj == 0 {
+			return ri != 0 && rj == 0
+		}
+		return ri < rj
+	})
+	return ordered
+}
+
+// previewCommand is the command line a task would run.
+func previewCommand(task *config.Task, workspaceDir string) string {
+	command, err := executor.ResolveCommand(task, workspaceDir, file)
+	if err != nil {
+		return fmt.Sprintf("(%v)", err)
+	}
+	return strings.Join(command, " ")
+}
+
+// taskSummary describes a task after its label: group, type and detail.
+func taskSummary(task *config.Task) string {
+	var parts []string
+	if group := task.GetGroupKind(); group != "" {
+		parts = append(parts, group)
+	}
+	if task.Type != "" {
+		parts = append(parts, task.Type)
+	}
+	summary := ""
+	if len(parts) > 0 {
+		summary = "[" + strings.Join(parts, ", ") + "]"
+	}
+	if task.Detail != "" {
+		summary = strings.TrimSpace(summary + " " + task.Detail)
+	}
+	return summary
+}
+
+// fuzzyScore matches every space-separated word of query as a subsequence of
+// text, ignoring case. Consecutive characters and characters at the start of
+// words score higher.
+func fuzzyScore(query string, text string) (int, bool) {
+	lower := []rune(strings.ToLower(text))
+	total := 0
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		score, ok := subsequenceScore([]rune(word), lower)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// subsequenceScore finds the best-scoring way to match query as a
+// subsequence of text.
+func subsequenceScore(query []rune, text []rune) (int, bool) {
+	if len(query) == 0 {
+		return 0, true
+	}
+	const none = -1
+	// best[i] is the best score of the query so far ending at text[i]
+	best := make([]int, len(text))
+	for i := range best {
+		best[i] = none
+		if text[i] == query[0] {
+			best[i] = 1 + wordStartBonus(text, i)
+		}
+	}
+	for _, r := range query[1:] {
+		next := make([]int, len(text))
+		for i := range next {
+			next[i] = none
+			if text[i] != r {
+				continue
+			}
+			for k := 0; k < i; k++ {
+				if best[k] == none {
+					continue
+				}
+				score := best[k] + 1 + wordStartBonus(text, i)
+				if k == i-1 {
+					score += 4
+				}
+				if score > next[i] {
+					next[i] = score
+				}
+			}
+		}
+		best = next
+	}
+
+	result := none
+	for _, score := range best {
+		if score > result {
+			result = score
+		}
+	}
+	return result, result != none
+}
+
+func wordStartBonus(text []rune, i int) int {
+	if i == 0 || !unicode.IsLetter(text[i-1]) && !unicode.IsDigit(text[i-1]) {
+		return 2
+	}
+	return 0
+}
+
+// filterTasksByQuery returns the tasks matching query, best matches first. Ties
+// keep the order of tasks.
+func filterTasksByQuery(tasks []*config.Task, query string) []*config.Task {
+	type match struct {
+		task  *config.Task
+		score int
+	}
+	var matches []match
+	for _, task := range tasks {
+		if score, ok := fuzzyScore(query, task.Label+" "+taskSummary(task)); ok {
+			matches = append(matches, match{task, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	filtered := make([]*config.Task, len(matches))
+	for i, m := range matches {
+		filtered[i] = m.task
+	}
+	return filtered
+}
+
+// picker is the state of the fuzzy-search task picker.
+type picker struct {
+	tasks    []*config.Task
+	verb     string
+	preview  func(*config.Task) string
+	previews map[string]string
+	query    []rune
+	matches  []*config.Task
+	selected int
+}
+
+func newPicker(tasks []*config.Task, verb string, preview func(*config.Task) string) *picker {
+	return &picker{tasks: tasks, verb: verb, preview: preview, previews: make(map[string]string), matches: tasks}
+}
+
+// pickerKey is a key press: a printable rune, or one of the named keys.
+type pickerKey struct {
+	name string
+	r    rune
+}
+
+// escapeKeys names the keys sent as escape sequences, by the part after
+// "ESC [" or "ESC O".
+var escapeKeys = map[string]string{
+	"A":  "up",
+	"B":  "down",
+	"H":  "home",
+	"F":  "end",
+	"1~": "home",
+	"4~": "end",
+	"5~": "pageup",
+	"6~": "pagedown",
+}
+
+// parseKeys splits raw terminal input into key presses.
+func parseKeys(input []byte) []pickerKey {
+	var keys []pickerKey
+	for len(input) > 0 {
+		switch {
+		case len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O'):
+			// Skip the parameters up to the final byte of the sequence
+			end := 2
+			for end < len(input)-1 && (input[end] >= '0' && input[end] <= '9' || input[end] == ';') {
+				end++
+			}
+			if name := escapeKeys[string(input[2:end+1])]; name != "" {
+				keys = append(keys, pickerKey{name: name})
+			}
+			input = input[end+1:]
+			continue
+		case input[0] == 0x1b:
+			keys = append(keys, pickerKey{name: "cancel"})
+		case input[0] == 3:
+			keys = append(keys, pickerKey{name: "cancel"})
+		case input[0] == '\r' || input[0] == '\n':
+			keys = append(keys, pickerKey{name: "enter"})
+		case input[0] == 0x7f || input[0] == 8:
+			keys = append(keys, pickerKey{name: "backspace"})
+		case input[0] == 0x15:
+			keys = append(keys, pickerKey{name: "clear"})
+		case input[0] == 0x10:
+			keys = append(keys, pickerKey{name: "up"})
+		case input[0] == 0x0e:
+			keys = append(keys, pickerKey{name: "down"})
+		case input[0] >= 0x20:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, pickerKey{r: r})
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// handleKey applies a key press. It returns the chosen task once the user
+// presses enter, or errNoTaskSelected if they cancel.
+func (p *picker) handleKey(key pickerKey) (*config.Task, error) {
+	switch key.name {
+	case "enter":
+		if len(p.matches) == 0 {
+			return nil, nil
+		}
+		return p.matches[p.selected], nil
+	case "cancel":
+		return nil, errNoTaskSelected
+	case "up":
+		if p.selected > 0 {
+			p.selected--
+		}
+	case "down":
+		if p.selected < len(p.matches)-1 {
+			p.selected++
+		}
+	case "backspace":
+		if len(p.query) > 0 {
+			p.setQuery(p.query[:len(p.query)-1])
+		}
+	case "clear":
+		p.setQuery(nil)
+	case "":
+		p.setQuery(append(p.query, key.r))
+	}
+	return nil, nil
+}
+
+func (p *picker) setQuery(query []rune) {
+	p.query = query
+	p.matches = filterTasksByQuery(p.tasks, string(query))
+	p.selected = 0
+}
+
+// render returns the picker's lines, cut to width: the prompt, the visible
+// matches, a counter and the command of the selected task.
+func (p *picker) render(width int) []string {
+	lines := []string{fmt.Sprintf("%s> %s", p.verb, string(p.query))}
+
+	// Scroll so that the selection stays visible
+	first := 0
+	if p.selected >= pickerHeight {
+		first = p.selected - pickerHeight + 1
+	}
+	labelWidth := 0
+	for _, task := range p.tasks {
+		if n := utf8.RuneCountInString(task.Label); n > labelWidth {
+			labelWidth = n
+		}
+	}
+	if labelWidth > 30 {
+		labelWidth = 30
+	}
+	for i := first; i < len(p.matches) && i < first+pickerHeight; i++ {
+		marker := "  "
+		if i == p.selected {
+			marker = "> "
+		}
+		task := p.matches[i]
+		lines = append(lines, strings.TrimRight(fmt.Sprintf("%s%-*s  %s", marker, labelWidth, task.Label, taskSummary(task)), " "))
+	}
+
+	lines = append(lines, fmt.Sprintf("  %d/%d", len(p.matches), len(p.tasks)))
+	if len(p.matches) > 0 {
+		task := p.matches[p.selected]
+		preview, ok := p.previews[task.Label]
+		if !ok {
+			preview = p.preview(task)
+			p.previews[task.Label] = preview
+		}
+		lines = append(lines, "  $ "+preview)
+	}
+
+	for i, line := range lines {
+		lines[i] = truncateRunes(line, width-1)
+	}
+	return lines
+}
+
+func truncateRunes(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// draw replaces the previous render on the terminal, leaving the cursor at
+// the end of the prompt.
+func (p *picker) draw(out io.Writer, width int) {
+	lines := p.render(width)
+	var b strings.Builder
+	b.WriteString("\r\x1b[J")
+	b.WriteString(strings.Join(lines, "\r\n"))
+	if len(lines) > 1 {
+		fmt.Fprintf(&b, "\x1b[%dA", len(lines)-1)
+	}
+	fmt.Fprintf(&b, "\r\x1b[%dC", utf8.RuneCountInString(lines[0]))
+	_, _ = io.WriteString(out, b.String())
+}
+
+// run reads key presses from in until a task is chosen or the picker is
+// cancelled, then clears it.
+func (p *picker) run(in io.Reader, out io.Writer, width int) (*config.Task, error) {
+	defer func() { _, _ = io.WriteString(out, "\r\x1b[J") }()
+
+	p.draw(out, width)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return nil, errNoTaskSelected
+		}
+		for _, key := range parseKeys(buf[:n]) {
+			task, err := p.handleKey(key)
+			if err != nil || task != nil {
+				return task, err
+			}
+		}
+		p.draw(out, width)
+	}
+}
+
+// numberedMenu lists tasks with numbers and reads the choice as a line. Text
+// that is not a number filters the list.
+func numberedMenu(in io.Reader, out io.Writer, tasks []*config.Task, verb string) (*config.Task, error) {
+	reader := bufio.NewReader(in)
+	shown := tasks
+	for {
+		fmt.Fprintf(out, "Select a task to %s:\n", verb)
+		for i, task := range shown {
+			fmt.Fprintln(out, strings.TrimRight(fmt.Sprintf("%3d) %-20s %s", i+1, task.Label, taskSummary(task)), " "))
+		}
+		fmt.Fprint(out, "Number, or text to filter (empty to cancel): ")
+
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			return nil, errNoTaskSelected
+		}
+		if n, convErr := strconv.Atoi(line); convErr == nil {
+			if n >= 1 && n <= len(shown) {
+				return shown[n-1], nil
+			}
+			fmt.Fprintf(out, "No task number %d\n", n)
+		} else if filtered := filterTasksByQuery(tasks, line); len(filtered) > 0 {
+			shown = filtered
+		} else {
+			fmt.Fprintf(out, "No task matches '%s'\n", line)
+		}
+		if err != nil {
+			return nil, errNoTaskSelected
+		}
+	}
+}
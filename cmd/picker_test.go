@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func pickerTestTasks() []config.Task {
+	return []config.Task{
+		{Label: "build", Type: "shell", Command: "go build ./...", Group: "build", Detail: "Compile everything"},
+		{Label: "test", Type: "shell", Command: "go test ./...", Group: "test"},
+		{Label: "lint", Type: "shell", Command: "golangci-lint run"},
+		{Label: "docs:serve", Type: "npm", Script: "docs"},
+	}
+}
+
+func taskLabels(tasks []*config.Task) string {
+	labels := make([]string, len(tasks))
+	for i, task := range tasks {
+		labels[i] = task.Label
+	}
+	return strings.Join(labels, ",")
+}
+
+func TestOrderByRecentUse(t *testing.T) {
+	ordered := orderByRecentUse(pickerTestTasks(), []string{"lint", "removed", "test"})
+	if got := taskLabels(ordered); got != "lint,test,build,docs:serve" {
+		t.Errorf("expected recent tasks first, got %s", got)
+	}
+}
+
+func TestFilterTasksByQuery(t *testing.T) {
+	tasks := orderByRecentUse(pickerTestTasks(), nil)
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"", "build,test,lint,docs:serve"},
+		{"ds", "docs:serve,build"},
+		{"serve", "docs:serve"},
+		{"compile", "build"},
+		{"npm", "docs:serve"},
+		{"TEST", "test"},
+		{"xyz", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := taskLabels(filterTasksByQuery(tasks, tt.query)); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys := parseKeys([]byte("a\x1b[B\x1b[A\x7f\r\x03é\x1b[5~\x1b[6~\x1bOH\x1b[1;5C"))
+	var names []string
+	for _, key := range keys {
+		if key.name == "" {
+			names = append(names, string(key.r))
+		} else {
+			names = append(names, key.name)
+		}
+	}
+	expected := "a down up backspace enter cancel é pageup pagedown home"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestPicker(t *testing.T) {
+	tasks := orderByRecentUse(pickerTestTasks(), nil)
+	preview := func(task *config.Task) string { return task.Command }
+
+	t.Run("filter and choose", func(t *testing.T) {
+		p := newPicker(tasks, "run", preview)
+		var out bytes.Buffer
+		task, err := p.run(strings.NewReader("t\x1b[B\r"), &out, 80)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := filterTasksByQuery(tasks, "t")[1]; task != expected {
+			t.Errorf("expected the second match %s to be chosen, got %s", expected.Label, task.Label)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		p := newPicker(tasks, "run", preview)
+		if _, err := p.run(strings.NewReader("\x1b"), &bytes.Buffer{}, 80); !errors.Is(err, errNoTaskSelected) {
+			t.Errorf("expected errNoTaskSelected, got %v", err)
+		}
+	})
+
+	t.Run("render", func(t *testing.T) {
+		p := newPicker(tasks, "run", preview)
+		p.handleKey(pickerKey{name: "down"})
+		lines := p.render(80)
+		expected := []string{
+			"run> ",
+			"  build       [build, shell] Compile everything",
+			"> test        [test, shell]",
+			"  lint        [shell]",
+			"  docs:serve  [npm]",
+			"  4/4",
+			"  $ go test ./...",
+		}
+		if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("expected\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+		}
+		if narrow := p.render(20); narrow[1] != "  build       [bui…" {
+			t.Errorf("expected lines to be cut to the width, got %q", narrow[1])
+		}
+	})
+}
+
+func TestNumberedMenu(t *testing.T) {
+	tasks := orderByRecentUse(pickerTestTasks(), nil)
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"number", "2\n", "test", false},
+		{"filter then number", "serve\n1\n", "docs:serve", false},
+		{"out of range then number", "9\n3\n", "lint", false},
+		{"empty cancels", "\n", "", true},
+		{"end of input", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			task, err := numberedMenu(strings.NewReader(tt.input), &out, tasks, "run")
+			if tt.wantErr {
+				if !errors.Is(err, errNoTaskSelected) {
+					t.Errorf("expected errNoTaskSelected, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if task.Label != tt.expected {
+				t.Errorf("expected %s, got %s\n%s", tt.expected, task.Label, out.String())
+			}
+		})
+	}
+}
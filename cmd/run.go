@@ -1,51 +1,48 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
 	"github.com/garaemon/tasks-json-cli/internal/discovery"
 	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/garaemon/tasks-json-cli/internal/history"
 	"github.com/spf13/cobra"
 )
 
 var dryRun bool
+var force bool
+var tracePath string
+var junitPath string
+var sarifPath string
+var eventsFormat string
+var eventsOutput string
 var workspaceFolder string
 var file string
 
 var runCommand = &cobra.Command{
-	Use:   "run <task-name>",
+	Use:   "run [task-name]",
 	Short: "Execute specified task",
-	Long:  `Execute a task defined in the tasks.json file.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Execute a task defined in the tasks.json file. Without a task name on a
+terminal, a picker lists the tasks, most recently run first. A glob like 'test:*'
+runs every matching task, sharing their dependencies.`,
+	Args:  cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeTaskLabels(1),
 	RunE:  executeRunCommand,
 	SilenceUsage: true,
 }
 
 func executeRunCommand(cmd *cobra.Command, args []string) error {
-	taskName := args[0]
-
-	// Determine workspace folder
-	var workspaceDir string
-	if workspaceFolder != "" {
-		workspaceDir = workspaceFolder
-	} else {
-		// Default to git root
-		currentDir, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
-		gitRoot, err := discovery.FindGitRoot(currentDir)
-		if err != nil {
-			// Fall back to current directory if git root not found
-			workspaceDir = currentDir
-		} else {
-			workspaceDir = gitRoot
-		}
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
 	}
 
 	if verbose {
@@ -61,32 +58,47 @@ func executeRunCommand(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Using tasks file: %s\n", tasksFilePath)
 	}
 
-	tasks, err := config.LoadTasks(tasksFilePath)
+	tasks, err := loadTasksFor(tasksFilePath, workspaceDir, args)
 	if err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
+		return err
 	}
 
-	var targetTask *config.Task
-	for i := range tasks {
-		if tasks[i].Label == taskName {
-			targetTask = &tasks[i]
-			break
+	var targets []*config.Task
+	if len(args) == 0 {
+		if !canPickTask() {
+			return fmt.Errorf("specify a task to run")
+		}
+		task, err := pickTask(tasks, workspaceDir, "run")
+		if err != nil {
+			return err
+		}
+		targets = []*config.Task{task}
+	} else {
+		targets, err = lookupTasks(tasks, args[0])
+		if err != nil {
+			return err
 		}
 	}
-
-	if targetTask == nil {
-		return fmt.Errorf("task '%s' not found", taskName)
+	// A glob is recorded as given, so that rerun selects the tasks again
+	runName := targets[0].Label
+	if len(targets) > 1 {
+		runName = args[0]
 	}
 
 	if dryRun {
-		// Resolve dependencies for dry-run display
-		resolver := executor.NewDependencyResolver(tasks)
-		executionOrder, err := resolver.ResolveExecutionOrder(targetTask.Label)
+		executionOrder, err := dryRunExecutionOrder(tasks, targets)
+		if err != nil {
+			return err
+		}
+		
+		cache, err := openCache(workspaceDir)
 		if err != nil {
-			return fmt.Errorf("failed to resolve dependencies: %w", err)
+			return err
 		}
+		opts := executor.RunOptions{WorkspaceDir: workspaceDir, File: file, Cache: cache, Force: force}
 		
 		fmt.Printf("Would execute the following tasks in order:\n")
+		ran := make(map[string]bool)
 		for i, task := range executionOrder {
 			// Apply variable substitution for dry-run display
 			substitutedTask := substituteVariablesForDryRun(task, workspaceDir, file)
@@ -97,16 +109,219 @@ func executeRunCommand(cmd *cobra.Command, args []string) error {
 			if len(substitutedTask.Args) > 0 {
 				fmt.Printf("   Args: %v\n", substitutedTask.Args)
 			}
+			if status := dryRunStatus(task, tasks, ran, opts); status != "" {
+				fmt.Printf("   Status: %s\n", status)
+			}
 			fmt.Println()
 		}
 		return nil
 	}
 
+	cache, err := openCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	opts := executor.RunOptions{WorkspaceDir: workspaceDir, File: file, Cache: cache, Force: force, Events: executor.NewEventBus()}
+	var trace *executor.Trace
+	if tracePath != "" {
+		trace = executor.NewTrace()
+		opts.Events.Subscribe(trace.Handle, executor.EventTaskStarted, executor.EventTaskReady, executor.EventTaskSkipped, executor.EventTaskFinished)
+	}
+	var report *executor.Report
+	if junitPath != "" || sarifPath != "" {
+		report = executor.NewReport()
+		opts.Events.Subscribe(report.Handle)
+	}
+	if eventsFormat != "" {
+		closeEvents, err := subscribeEventStream(opts.Events, eventsFormat, eventsOutput)
+		if err != nil {
+			return err
+		}
+		defer closeEvents()
+	}
+
 	if !quiet {
-		fmt.Printf("Executing task: %s\n", targetTask.Label)
+		for _, task := range targets {
+			fmt.Printf("Executing task: %s\n", task.Label)
+		}
+	}
+
+	recorder := &history.Recorder{}
+	opts.Events.Subscribe(recorder.Handle, executor.EventTaskSkipped, executor.EventTaskFinished)
+
+	start := time.Now()
+	runErr := executor.RunAll(context.Background(), targets, tasks, opts)
+	recordRun(cmd, history.Entry{
+		Task:      runName,
+		Workspace: workspaceDir,
+		TasksFile: tasksFilePath,
+		File:      file,
+		Start:     start,
+		Duration:  time.Since(start),
+		Tasks:     recorder.Results(),
+	}, runErr)
+
+	if trace != nil {
+		if err := writeOutputFile(tracePath, "trace", trace.WriteJSON); err != nil {
+			return err
+		}
+		if !quiet {
+			printCriticalPath(os.Stdout, trace)
+		}
+	}
+	if junitPath != "" {
+		writeJUnit := func(w io.Writer) error { return report.WriteJUnit(w, runName) }
+		if err := writeOutputFile(junitPath, "JUnit report", writeJUnit); err != nil {
+			return err
+		}
+	}
+	if sarifPath != "" {
+		writeSARIF := func(w io.Writer) error { return report.WriteSARIF(w, workspaceDir) }
+		if err := writeOutputFile(sarifPath, "SARIF report", writeSARIF); err != nil {
+			return err
+		}
+	}
+	return runErr
+}
+
+// dryRunExecutionOrder lists the tasks a run of targets would execute, with
+// dependencies shared between them once.
+func dryRunExecutionOrder(tasks []config.Task, targets []*config.Task) ([]*config.Task, error) {
+	resolver := executor.NewDependencyResolver(tasks)
+	var executionOrder []*config.Task
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		order, err := resolver.ResolveExecutionOrder(target.Label)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve dependencies: %w", err)
+		}
+		for _, task := range order {
+			if !seen[task.Label] {
+				seen[task.Label] = true
+				executionOrder = append(executionOrder, task)
+			}
+		}
+	}
+	return executionOrder, nil
+}
+
+// subscribeEventStream writes the events of a run to path, or to file
+// descriptor 3 if path is empty, for editors and dashboards to follow.
+func subscribeEventStream(events *executor.EventBus, format string, path string) (func(), error) {
+	if format != "jsonl" {
+		return nil, fmt.Errorf("unknown events format '%s', supported formats: jsonl", format)
 	}
 
-	return executor.RunTaskWithDependencies(targetTask, tasks, workspaceDir, file)
+	var out *os.File
+	if path == "" {
+		fd, err := openEventsFD()
+		if err != nil {
+			return nil, err
+		}
+		out = fd
+	} else {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write events: %w", err)
+		}
+		out = f
+	}
+
+	unsubscribe := events.Subscribe(executor.WriteJSONLines(out))
+	return func() {
+		unsubscribe()
+		if path != "" {
+			_ = out.Close()
+		}
+	}, nil
+}
+
+// writeOutputFile creates path and fills it with write; kind names the
+// contents in errors.
+func writeOutputFile(path string, kind string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", kind, err)
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", kind, err)
+	}
+	return f.Close()
+}
+
+// printCriticalPath prints the longest chain of dependent tasks in a trace
+// and how much of the run it took.
+func printCriticalPath(w io.Writer, trace *executor.Trace) {
+	path := trace.CriticalPath()
+	if len(path) == 0 {
+		return
+	}
+
+	var start, end time.Time
+	for _, span := range trace.Spans() {
+		if start.IsZero() || span.Start.Before(start) {
+			start = span.Start
+		}
+		if span.End.After(end) {
+			end = span.End
+		}
+	}
+	var critical time.Duration
+	width := 0
+	for _, span := range path {
+		critical += span.Duration()
+		if len(span.Label) > width {
+			width = len(span.Label)
+		}
+	}
+
+	fmt.Fprintf(w, "\nCritical path: %s of %s\n", critical.Round(time.Millisecond), end.Sub(start).Round(time.Millisecond))
+	for _, span := range path {
+		note := ""
+		switch {
+		case span.Status != "":
+			note = " (" + span.Status + ")"
+		case !span.Ready.IsZero():
+			note = " (until ready)"
+		case span.ExitCode != 0:
+			note = fmt.Sprintf(" (exit code %d)", span.ExitCode)
+		}
+		fmt.Fprintf(w, "  %-*s %8s%s\n", width, span.Label, span.Duration().Round(time.Millisecond), note)
+	}
+}
+
+// dryRunStatus tells whether a task would be skipped and why, recording in
+// ran whether it would run. Tasks without x-inputs, x-outputs or x-cache
+// always run and get no status.
+func dryRunStatus(task *config.Task, tasks []config.Task, ran map[string]bool, opts executor.RunOptions) string {
+	ran[task.Label] = true
+	if task.Cache && opts.Cache != nil && executor.IsCacheable(task) {
+		key, err := executor.CacheKey(task, tasks, opts)
+		if err != nil {
+			return fmt.Sprintf("run (cache key unavailable: %v)", err)
+		}
+		if _, ok := opts.Cache.Lookup(key); ok {
+			return "restore from cache"
+		}
+		return "run (not in cache)"
+	}
+	if len(task.Inputs) == 0 && len(task.Outputs) == 0 {
+		return ""
+	}
+	if task.Cache {
+		return "run (--no-cache)"
+	}
+	if opts.Force {
+		return "run (--force)"
+	}
+	upToDate, reason := executor.CheckUpToDate(task, ran, opts.WorkspaceDir)
+	if upToDate {
+		ran[task.Label] = false
+		return fmt.Sprintf("skip, up to date (%s)", reason)
+	}
+	return fmt.Sprintf("run (%s)", reason)
 }
 
 // substituteEnvVariablesForDryRun replaces ${env:VARNAME} patterns with environment variable values
@@ -198,6 +413,14 @@ func substituteVariablesForDryRun(task *config.Task, workspaceDir string, file s
 func init() {
 	runCommand.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be executed without running")
 	runCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	runCommand.Flags().StringVar(&tracePath, "trace", "", "write a Chrome trace-event timeline of the run to this file")
+	runCommand.Flags().StringVar(&junitPath, "junit", "", "write a JUnit XML report with one test case per task to this file")
+	runCommand.Flags().StringVar(&sarifPath, "sarif", "", "write the problems found by problem matchers as SARIF to this file")
+	runCommand.Flags().StringVar(&eventsFormat, "events", "", "stream the events of the run in this format: jsonl")
+	runCommand.Flags().StringVar(&eventsOutput, "events-output", "", "file to write --events to (defaults to file descriptor 3)")
+	runCommand.Flags().BoolVar(&force, "force", false, "run tasks even if their outputs are up to date")
+	runCommand.Flags().BoolVar(&noCache, "no-cache", false, "run tasks with \"x-cache\" instead of restoring them from the cache")
+	runCommand.Flags().BoolVar(&looseMatch, "loose", false, "match task names case-insensitively or by a unique prefix")
 	runCommand.Flags().StringVar(&file, "file", "", "file path to replace ${file} variable")
 	rootCmd.AddCommand(runCommand)
 }
\ No newline at end of file
@@ -7,7 +7,10 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
 	"github.com/spf13/cobra"
 )
 
@@ -563,4 +566,48 @@ func TestExecuteRunCommand_WithFile(t *testing.T) {
 	if !strings.Contains(output, "Command: fmt src/main.go") {
 		t.Errorf("expected file variable substitution in output, got %s", output)
 	}
-}
\ No newline at end of file
+}
+
+func TestDryRunStatus(t *testing.T) {
+	workspaceDir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for name, offset := range map[string]time.Duration{"api.proto": 0, "gen/api.go": time.Minute, "main.go": 2 * time.Minute, "app": time.Minute} {
+		path := filepath.Join(workspaceDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, base.Add(offset), base.Add(offset)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tasks := []config.Task{
+		{Label: "gen", Inputs: []string{"*.proto"}, Outputs: []string{"gen"}},
+		{Label: "build", Inputs: []string{"*.go"}, Outputs: []string{"app"}, DependsOn: "gen"},
+		{Label: "test", DependsOn: "build"},
+	}
+
+	ran := make(map[string]bool)
+	opts := executor.RunOptions{WorkspaceDir: workspaceDir}
+	expected := []string{
+		"skip, up to date (outputs are newer than inputs)",
+		"run (input 'main.go' is newer than output 'app')",
+		"",
+	}
+	for i := range tasks {
+		if got := dryRunStatus(&tasks[i], tasks, ran, opts); got != expected[i] {
+			t.Errorf("task %s: expected status %q, got %q", tasks[i].Label, expected[i], got)
+		}
+	}
+	if ran["gen"] || !ran["build"] || !ran["test"] {
+		t.Errorf("expected only gen to be skipped, got %v", ran)
+	}
+
+	opts.Force = true
+	if got := dryRunStatus(&tasks[0], tasks, map[string]bool{}, opts); got != "run (--force)" {
+		t.Errorf("expected --force to run up to date tasks, got %q", got)
+	}
+}
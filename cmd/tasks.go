@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+)
+
+// resolveWorkspaceDir returns the --workspace-folder value, falling back to the
+// git root of the current directory and then to the current directory itself.
+func resolveWorkspaceDir() (string, error) {
+	if workspaceFolder != "" {
+		return workspaceFolder, nil
+	}
+
+	currentDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	gitRoot, err := discovery.FindGitRoot(currentDir)
+	if err != nil {
+		// Fall back to current directory if git root not found
+		return currentDir, nil
+	}
+	return gitRoot, nil
+}
+
+// loadSettings reads the settings.json that sits next to the tasks file.
+func loadSettings(tasksFilePath string) (*config.Settings, error) {
+	settings, err := config.LoadSettings(discovery.FindSettingsFile(tasksFilePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+	return settings, nil
+}
+
+// loadAllTasks loads the tasks defined in tasks.json and appends the tasks
+// auto-detected in the workspace by the enabled task providers.
+func loadAllTasks(tasksFilePath string, workspaceDir string) ([]config.Task, error) {
+	return loadTasksFor(tasksFilePath, workspaceDir, nil)
+}
+
+// loadTasksFor is loadAllTasks for commands working on the named tasks. The
+// workspace is only scanned for tasks when tasks.json does not define the
+// named tasks and their dependencies, or when no task is named.
+func loadTasksFor(tasksFilePath string, workspaceDir string, names []string) ([]config.Task, error) {
+	tasks, err := config.LoadTasks(tasksFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	settings, err := loadSettings(tasksFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 || !definesTasks(tasks, names) {
+		// Tasks of the files that could be read are kept
+		detected, err := discovery.DetectTasks(workspaceDir, settings)
+		if err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", line)
+			}
+		}
+		tasks = discovery.MergeTasks(tasks, detected)
+	}
+
+	packageManager := settings.GetString("npm.packageManager", "auto")
+	for i := range tasks {
+		if tasks[i].Type == "npm" {
+			tasks[i].PackageManager = packageManager
+		}
+	}
+	return tasks, nil
+}
+
+// definesTasks reports whether tasks has a task labeled exactly like each
+// name, and the tasks they depend on.
+func definesTasks(tasks []config.Task, names []string) bool {
+	byLabel := make(map[string]*config.Task, len(tasks))
+	for i := range tasks {
+		byLabel[tasks[i].Label] = &tasks[i]
+	}
+	seen := make(map[string]bool)
+	pending := append([]string(nil), names...)
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		task, ok := byLabel[name]
+		if !ok {
+			return false
+		}
+		pending = append(pending, task.GetDependencies()...)
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAllTasks_IncludesDetectedNpmScripts(t *testing.T) {
+	tempDir := t.TempDir()
+
+	vscodeDir := filepath.Join(tempDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(vscodeDir, "tasks.json")
+	if err := os.WriteFile(tasksFile, []byte(`{
+		"version": "2.0.0",
+		"tasks": [
+			{
+				"label": "all",
+				"type": "shell",
+				"command": "echo done",
+				"dependsOn": ["npm: build"]
+			}
+		]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"build": "tsc"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := loadAllTasks(tasksFile, tempDir)
+	if err != nil {
+		t.Fatalf("loadAllTasks failed: %v", err)
+	}
+
+	if len(tasks) != 3 {
+		t.Fatalf("expected 3 tasks (all, npm: build, npm: install), got %d", len(tasks))
+	}
+	if tasks[0].Label != "all" {
+		t.Errorf("expected tasks.json tasks first, got %q", tasks[0].Label)
+	}
+	if tasks[1].Label != "npm: build" || tasks[1].Type != "npm" {
+		t.Errorf("expected detected npm task, got %+v", tasks[1])
+	}
+}
+
+func TestLoadAllTasks_KeepsTasksOnDetectionErrors(t *testing.T) {
+	tempDir := t.TempDir()
+
+	vscodeDir := filepath.Join(tempDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(vscodeDir, "tasks.json")
+	if err := os.WriteFile(tasksFile, []byte(`{"version": "2.0.0", "tasks": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"build": "tsc"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing.js", filepath.Join(tempDir, "gulpfile.js")); err != nil {
+		t.Fatal(err)
+	}
+
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	tasks, err := loadAllTasks(tasksFile, tempDir)
+	os.Stderr = oldStderr
+	_ = w.Close()
+	printed, _ := io.ReadAll(r)
+
+	if err != nil {
+		t.Fatalf("loadAllTasks failed: %v", err)
+	}
+	if _, err := lookupTask(tasks, "npm: build"); err != nil {
+		t.Errorf("expected the npm task to be kept: %v", err)
+	}
+	if !strings.HasPrefix(string(printed), "Warning: gulp task detection: failed to read") {
+		t.Errorf("expected a warning about the gulpfile, got %q", printed)
+	}
+}
+
+func TestLoadAllTasks_DetectionDisabled(t *testing.T) {
+	tempDir := t.TempDir()
+
+	vscodeDir := filepath.Join(tempDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(vscodeDir, "tasks.json")
+	if err := os.WriteFile(tasksFile, []byte(`{"version": "2.0.0", "tasks": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vscodeDir, "settings.json"), []byte(`{"npm.autoDetect": "off"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"build": "tsc"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := loadAllTasks(tasksFile, tempDir)
+	if err != nil {
+		t.Fatalf("loadAllTasks failed: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected no tasks with npm.autoDetect off, got %d", len(tasks))
+	}
+}
+
+func TestLoadAllTasks_NpmPackageManager(t *testing.T) {
+	tempDir := t.TempDir()
+
+	vscodeDir := filepath.Join(tempDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(vscodeDir, "tasks.json")
+	if err := os.WriteFile(tasksFile, []byte(`{"version": "2.0.0", "tasks": [{"label": "lint", "type": "npm", "script": "lint"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vscodeDir, "settings.json"), []byte(`{"npm.packageManager": "yarn"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"build": "tsc"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := loadAllTasks(tasksFile, tempDir)
+	if err != nil {
+		t.Fatalf("loadAllTasks failed: %v", err)
+	}
+	for _, task := range tasks {
+		if task.PackageManager != "yarn" {
+			t.Errorf("expected %s to run with yarn, got %q", task.Label, task.PackageManager)
+		}
+	}
+}
+
+func TestLoadTasksFor_DetectsOnlyWhenNeeded(t *testing.T) {
+	tempDir := t.TempDir()
+
+	vscodeDir := filepath.Join(tempDir, ".vscode")
+	if err := os.MkdirAll(vscodeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	tasksFile := filepath.Join(vscodeDir, "tasks.json")
+	if err := os.WriteFile(tasksFile, []byte(`{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "build", "type": "shell", "command": "make", "dependsOn": "gen"},
+			{"label": "gen", "type": "shell", "command": "buf generate"},
+			{"label": "ci", "type": "shell", "command": "echo done", "dependsOn": ["build", "npm: lint"]}
+		]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "package.json"), []byte(`{"scripts": {"lint": "eslint ."}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		names    []string
+		detected bool
+	}{
+		{[]string{"build"}, false},
+		{[]string{"ci"}, true},
+		{[]string{"npm: lint"}, true},
+		{[]string{"bu*"}, true},
+		{nil, true},
+	}
+	for _, tt := range tests {
+		tasks, err := loadTasksFor(tasksFile, tempDir, tt.names)
+		if err != nil {
+			t.Fatalf("loadTasksFor(%v) failed: %v", tt.names, err)
+		}
+		if detected := len(tasks) > 3; detected != tt.detected {
+			t.Errorf("loadTasksFor(%v): expected detection %v, got %d tasks", tt.names, tt.detected, len(tasks))
+		}
+	}
+}
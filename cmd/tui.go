@@ -0,0 +1,681 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/garaemon/tasks-json-cli/internal/history"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var tuiCommand = &cobra.Command{
+	Use:   "tui",
+	Short: "Run and monitor tasks in a full-screen dashboard",
+	Long: `Show every task with its status and duration next to the output of the
+selected task. Tasks are started, rerun and cancelled from the keyboard, one run
+at a time; the dependencies of a run are listed with their own status and
+output.
+
+  up/down, k/j     select a task
+  enter, s         run the selected task and its dependencies
+  r                run the last task again
+  c                cancel the run
+  PgUp/PgDn, g/G   scroll the output, or jump to its start or end
+  /, n/N           search the output, go to the next or previous match
+  q, Esc           quit, stopping the run`,
+	Args:         cobra.NoArgs,
+	RunE:         executeTUICommand,
+	SilenceUsage: true,
+}
+
+// Statuses of the tasks in the dashboard. Tasks that did not run yet have none.
+const (
+	statusPending   = "pending"
+	statusRunning   = "running"
+	statusPassed    = "passed"
+	statusFailed    = "failed"
+	statusSkipped   = "skipped"
+	statusStopped   = "stopped"
+	statusCancelled = "cancelled"
+)
+
+// maxOutputLines is how many output lines the dashboard keeps per task.
+const maxOutputLines = 10000
+
+// ansiEscape matches the color and cursor sequences in task output, which
+// would break the layout.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b[()][0-9A-Za-z]|\x1b[=>]`)
+
+// dashboardLine is a line of task output.
+type dashboardLine struct {
+	text   string
+	stderr bool
+}
+
+// dashboardTask is the state of a task in the dashboard.
+type dashboardTask struct {
+	task     *config.Task
+	status   string
+	note     string
+	start    time.Time
+	duration time.Duration
+	problems int
+	output   []dashboardLine
+}
+
+// dashboardAction is what the dashboard asks its caller to do after a key.
+type dashboardAction int
+
+const (
+	actionNone dashboardAction = iota
+	actionStart
+	actionCancel
+	actionQuit
+)
+
+// dashboard is the state of the tui command, updated from key presses and
+// executor events and rendered as lines.
+type dashboard struct {
+	tasks    []*dashboardTask
+	byLabel  map[string]*dashboardTask
+	selected int
+	listTop  int
+	// scroll is how many lines the output is scrolled up from its end
+	scroll    int
+	pageSize  int
+	searching bool
+	query     []rune
+	match     int
+	// running is the label of the task being run, last the one run last
+	running  string
+	last     string
+	runStart time.Time
+	summary  string
+	message  string
+	title    string
+	now      func() time.Time
+}
+
+func newDashboard(tasks []config.Task, title string) *dashboard {
+	d := &dashboard{byLabel: make(map[string]*dashboardTask), match: -1, pageSize: 10, title: title, now: time.Now}
+	for i := range tasks {
+		t := &dashboardTask{task: &tasks[i]}
+		d.tasks = append(d.tasks, t)
+		d.byLabel[t.task.Label] = t
+	}
+	return d
+}
+
+func (d *dashboard) current() *dashboardTask {
+	if len(d.tasks) == 0 {
+		return nil
+	}
+	return d.tasks[d.selected]
+}
+
+// runStarted records that label is being run.
+func (d *dashboard) runStarted(label string) {
+	d.running = label
+	d.last = label
+	d.runStart = d.now()
+	d.summary = ""
+}
+
+// handleEvent applies an event of the current run.
+func (d *dashboard) handleEvent(event executor.Event) {
+	if event.Type == executor.EventRunFinished {
+		d.runFinished(event)
+		return
+	}
+	if event.Type == executor.EventPlan {
+		for _, label := range event.Tasks {
+			if t := d.byLabel[label]; t != nil {
+				*t = dashboardTask{task: t.task, status: statusPending}
+			}
+		}
+		if t := d.current(); t != nil && t.status == statusPending {
+			d.scroll, d.match = 0, -1
+		}
+		return
+	}
+
+	t := d.byLabel[event.Task]
+	if t == nil {
+		return
+	}
+	switch event.Type {
+	case executor.EventTaskStarted:
+		t.status = statusRunning
+		t.start = event.Time
+	case executor.EventTaskReady:
+		t.note = "ready"
+	case executor.EventOutput:
+		t.appendOutput(event.Line, event.Stream == "stderr")
+		if t == d.current() && d.scroll > 0 {
+			// Keep the scrolled view where it is while lines are added
+			d.scroll++
+		}
+	case executor.EventDiagnostic:
+		t.problems++
+	case executor.EventTaskSkipped:
+		t.status = statusSkipped
+		t.note = event.Status
+	case executor.EventTaskFinished:
+		t.duration = event.Duration
+		switch {
+		case event.Stopped:
+			t.status = statusStopped
+		case event.Error != "" || (event.ExitCode != nil && *event.ExitCode != 0):
+			t.status = statusFailed
+			if event.ExitCode != nil && *event.ExitCode > 0 {
+				t.note = fmt.Sprintf("exit %d", *event.ExitCode)
+			}
+		default:
+			t.status = statusPassed
+		}
+	}
+}
+
+func (d *dashboard) runFinished(event executor.Event) {
+	for _, t := range d.tasks {
+		if t.status == statusPending {
+			t.status = ""
+			if event.Status == "cancelled" {
+				t.status = statusCancelled
+			}
+		}
+	}
+	d.summary = fmt.Sprintf("%s %s in %s", d.running, event.Status, event.Duration.Round(time.Millisecond))
+	d.running = ""
+}
+
+func (t *dashboardTask) appendOutput(line string, stderr bool) {
+	if i := strings.LastIndex(line, "\r"); i >= 0 {
+		// Progress bars redraw the line, only its final state is kept
+		line = line[i+1:]
+	}
+	line = ansiEscape.ReplaceAllString(line, "")
+	line = strings.ReplaceAll(line, "\t", "    ")
+	t.output = append(t.output, dashboardLine{text: line, stderr: stderr})
+	if len(t.output) > maxOutputLines {
+		t.output = t.output[len(t.output)-maxOutputLines:]
+	}
+}
+
+// handleKey applies a key press and returns what the caller should do, with
+// the task to start for actionStart.
+func (d *dashboard) handleKey(key pickerKey) (dashboardAction, *config.Task) {
+	d.message = ""
+	if d.searching {
+		d.handleSearchKey(key)
+		return actionNone, nil
+	}
+
+	switch {
+	case key.name == "up" || key.r == 'k':
+		d.selectTask(d.selected - 1)
+	case key.name == "down" || key.r == 'j':
+		d.selectTask(d.selected + 1)
+	case key.name == "pageup":
+		d.scrollBy(d.pageSize)
+	case key.name == "pagedown":
+		d.scrollBy(-d.pageSize)
+	case key.name == "home" || key.r == 'g':
+		d.scrollBy(maxOutputLines)
+	case key.name == "end" || key.r == 'G':
+		d.scroll = 0
+	case key.name == "enter" || key.r == 's':
+		if t := d.current(); t != nil {
+			return d.start(t.task)
+		}
+	case key.r == 'r':
+		if d.last == "" {
+			d.message = "Nothing to rerun yet"
+			return actionNone, nil
+		}
+		return d.start(d.byLabel[d.last].task)
+	case key.r == 'c':
+		if d.running == "" {
+			d.message = "Nothing is running"
+			return actionNone, nil
+		}
+		d.message = fmt.Sprintf("Cancelling %s...", d.running)
+		return actionCancel, nil
+	case key.r == '/':
+		d.searching = true
+		d.query = nil
+		d.match = -1
+	case key.r == 'n':
+		d.findMatch(1)
+	case key.r == 'N':
+		d.findMatch(-1)
+	case key.name == "cancel" || key.r == 'q':
+		return actionQuit, nil
+	}
+	return actionNone, nil
+}
+
+func (d *dashboard) handleSearchKey(key pickerKey) {
+	switch key.name {
+	case "enter":
+		d.searching = false
+		d.match = -1
+		d.findMatch(-1)
+	case "cancel":
+		d.searching = false
+		d.query = nil
+		d.match = -1
+	case "backspace":
+		if len(d.query) > 0 {
+			d.query = d.query[:len(d.query)-1]
+		}
+	case "clear":
+		d.query = nil
+	case "":
+		d.query = append(d.query, key.r)
+	}
+}
+
+func (d *dashboard) start(task *config.Task) (dashboardAction, *config.Task) {
+	if d.running != "" {
+		d.message = fmt.Sprintf("%s is running, press c to cancel it first", d.running)
+		return actionNone, nil
+	}
+	return actionStart, task
+}
+
+func (d *dashboard) selectTask(i int) {
+	if i < 0 || i >= len(d.tasks) {
+		return
+	}
+	d.selected = i
+	d.scroll = 0
+	d.match = -1
+}
+
+func (d *dashboard) scrollBy(lines int) {
+	t := d.current()
+	if t == nil {
+		return
+	}
+	d.scroll += lines
+	if maxScroll := len(t.output) - d.pageSize; d.scroll > maxScroll {
+		d.scroll = maxScroll
+	}
+	if d.scroll < 0 {
+		d.scroll = 0
+	}
+}
+
+// findMatch moves to the next output line containing the search query in
+// direction, 1 for newer and -1 for older lines, starting from the end
+// when there is no current match.
+func (d *dashboard) findMatch(direction int) {
+	t := d.current()
+	if t == nil || len(d.query) == 0 {
+		return
+	}
+	query := strings.ToLower(string(d.query))
+	i := d.match
+	if i < 0 {
+		i = len(t.output)
+	}
+	for i += direction; i >= 0 && i < len(t.output); i += direction {
+		if strings.Contains(strings.ToLower(t.output[i].text), query) {
+			d.match = i
+			// Show the match at the top of the pane where possible
+			d.scroll = 0
+			if rest := len(t.output) - i - d.pageSize; rest > 0 {
+				d.scroll = rest
+			}
+			return
+		}
+	}
+	d.message = fmt.Sprintf("No more matches for '%s'", string(d.query))
+}
+
+// render returns the lines of the screen: a header, the task list next to
+// the output of the selected task, and a footer with the keys or the search.
+func (d *dashboard) render(width, height int) []string {
+	if height < 3 {
+		height = 3
+	}
+	body := height - 2
+
+	header := " " + d.title
+	switch {
+	case d.running != "":
+		header += fmt.Sprintf("  running %s %s", d.running, d.now().Sub(d.runStart).Round(100*time.Millisecond))
+	case d.summary != "":
+		header += "  " + d.summary
+	}
+	lines := []string{"\x1b[7m" + padRunes(truncateRunes(header, width), width) + "\x1b[0m"}
+
+	listWidth := 0
+	for _, t := range d.tasks {
+		if n := utf8.RuneCountInString(t.task.Label); n > listWidth {
+			listWidth = n
+		}
+	}
+	listWidth += 12
+	if max := width / 3; listWidth > max {
+		listWidth = max
+	}
+	list := d.renderList(listWidth, body)
+	output := d.renderOutput(width-listWidth-1, body)
+	for i := 0; i < body; i++ {
+		lines = append(lines, list[i]+"\x1b[2m│\x1b[0m"+output[i])
+	}
+
+	footer := "enter run  r rerun  c cancel  / search  n/N next/prev  PgUp/PgDn scroll  q quit"
+	switch {
+	case d.searching:
+		footer = "/" + string(d.query)
+	case d.message != "":
+		footer = d.message
+	}
+	lines = append(lines, truncateRunes(footer, width))
+	return lines
+}
+
+// renderList renders the task list, each row exactly width wide.
+func (d *dashboard) renderList(width, height int) []string {
+	if d.selected < d.listTop {
+		d.listTop = d.selected
+	}
+	if d.selected >= d.listTop+height {
+		d.listTop = d.selected - height + 1
+	}
+
+	lines := make([]string, height)
+	for row := 0; row < height; row++ {
+		i := d.listTop + row
+		if i >= len(d.tasks) {
+			lines[row] = strings.Repeat(" ", width)
+			continue
+		}
+		t := d.tasks[i]
+		duration := ""
+		switch {
+		case t.status == statusRunning:
+			duration = d.now().Sub(t.start).Round(100 * time.Millisecond).String()
+		case t.duration > 0:
+			duration = t.duration.Round(time.Millisecond).String()
+		}
+		symbol, color := statusSymbol(t.status)
+		labelWidth := width - 3 - utf8.RuneCountInString(duration)
+		if labelWidth < 1 {
+			labelWidth = 1
+		}
+		text := padRunes(truncateRunes(t.task.Label, labelWidth), labelWidth) + " " + duration
+		if i == d.selected {
+			lines[row] = "\x1b[7m" + symbol + " " + text + "\x1b[0m"
+		} else {
+			lines[row] = color + symbol + "\x1b[0m " + text
+		}
+	}
+	return lines
+}
+
+func statusSymbol(status string) (string, string) {
+	switch status {
+	case statusPending:
+		return "·", "\x1b[2m"
+	case statusRunning:
+		return "●", "\x1b[33m"
+	case statusPassed:
+		return "✓", "\x1b[32m"
+	case statusFailed:
+		return "✗", "\x1b[31m"
+	case statusSkipped:
+		return "↷", "\x1b[36m"
+	case statusStopped, statusCancelled:
+		return "■", "\x1b[2m"
+	}
+	return " ", ""
+}
+
+// renderOutput renders the status and output of the selected task, each
+// line at most width wide.
+func (d *dashboard) renderOutput(width, height int) []string {
+	lines := make([]string, height)
+	t := d.current()
+	if t == nil || width <= 1 {
+		return lines
+	}
+	d.pageSize = height - 1
+
+	status := t.status
+	if status == "" {
+		status = "not run"
+	}
+	var details []string
+	if t.note != "" {
+		details = append(details, t.note)
+	}
+	if t.problems > 0 {
+		details = append(details, countOf(t.problems, "problem"))
+	}
+	details = append(details, countOf(len(t.output), "line"))
+	lines[0] = "\x1b[1m" + truncateRunes(fmt.Sprintf(" %s: %s (%s)", t.task.Label, status, strings.Join(details, ", ")), width) + "\x1b[0m"
+
+	end := len(t.output) - d.scroll
+	first := end - d.pageSize
+	if first < 0 {
+		first = 0
+	}
+	query := strings.ToLower(string(d.query))
+	for row, i := 1, first; i < end && row < height; row, i = row+1, i+1 {
+		line := truncateRunes(" "+t.output[i].text, width)
+		switch {
+		case i == d.match:
+			line = "\x1b[7m" + line + "\x1b[0m"
+		case query != "" && !d.searching:
+			line = highlight(line, query)
+		}
+		if t.output[i].stderr {
+			line = "\x1b[31m" + line + "\x1b[0m"
+		}
+		lines[row] = line
+	}
+	return lines
+}
+
+// countOf renders "1 line", "2 lines" and so on.
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// highlight underlines the occurrences of the lower-case query in line.
+func highlight(line string, query string) string {
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		return line
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i] + "\x1b[4m" + line[i:i+len(query)] + "\x1b[24m")
+		line, lower = line[i+len(query):], lower[i+len(query):]
+	}
+}
+
+func padRunes(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// draw redraws the whole screen.
+func (d *dashboard) draw(out io.Writer) {
+	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		width, height = 80, 24
+	}
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range d.render(width, height) {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line + "\x1b[0m\x1b[K")
+	}
+	_, _ = io.WriteString(out, b.String())
+}
+
+// dashboardRun is a finished run, reported to the loop for recording.
+type dashboardRun struct {
+	entry history.Entry
+	err   error
+}
+
+func executeTUICommand(cmd *cobra.Command, args []string) error {
+	if !canPickTask() {
+		return fmt.Errorf("the dashboard needs a terminal")
+	}
+
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
+	}
+	tasksFilePath, err := discovery.FindTasksFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to find tasks file: %w", err)
+	}
+	tasks, err := loadAllTasks(tasksFilePath, workspaceDir)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		return fmt.Errorf("no tasks found")
+	}
+	cache, err := openCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("failed to set up the terminal: %w", err)
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+	// Use the alternate screen and hide the cursor while the dashboard is shown
+	_, _ = io.WriteString(os.Stdout, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = io.WriteString(os.Stdout, "\x1b[?25h\x1b[?1049l") }()
+
+	keys := make(chan []pickerKey)
+	go func() {
+		buf := make([]byte, 256)
+		for {
+			n, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- parseKeys(buf[:n])
+		}
+	}()
+
+	d := newDashboard(tasks, "tasks-json-cli "+tasksFilePath)
+	events := make(chan executor.Event, 256)
+	finished := make(chan dashboardRun, 1)
+	var cancelRun context.CancelFunc
+	start := func(task *config.Task) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancelRun = cancel
+		bus := executor.NewEventBus()
+		bus.Subscribe(func(event executor.Event) { events <- event })
+		recorder := &history.Recorder{}
+		bus.Subscribe(recorder.Handle, executor.EventTaskSkipped, executor.EventTaskFinished)
+		d.runStarted(task.Label)
+
+		go func() {
+			began := time.Now()
+			err := executor.Run(ctx, task, tasks, executor.RunOptions{WorkspaceDir: workspaceDir, Cache: cache, Events: bus, Silent: true})
+			finished <- dashboardRun{
+				entry: history.Entry{Task: task.Label, Workspace: workspaceDir, TasksFile: tasksFilePath, Start: began, Duration: time.Since(began), Tasks: recorder.Results()},
+				err:   err,
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+	quitting := false
+	for {
+		d.draw(os.Stdout)
+		select {
+		case pressed, ok := <-keys:
+			if !ok {
+				// Without input there is no way to go on but to quit
+				keys = nil
+				pressed = []pickerKey{{name: "cancel"}}
+			}
+			for _, key := range pressed {
+				action, task := d.handleKey(key)
+				switch action {
+				case actionStart:
+					start(task)
+				case actionCancel:
+					cancelRun()
+				case actionQuit:
+					quitting = true
+				}
+			}
+			if quitting {
+				if cancelRun == nil {
+					return nil
+				}
+				cancelRun()
+				d.message = "Stopping the run..."
+			}
+		case event := <-events:
+			d.handleEvent(event)
+			// Apply what else arrived before redrawing
+			for drained := false; !drained; {
+				select {
+				case event := <-events:
+					d.handleEvent(event)
+				default:
+					drained = true
+				}
+			}
+		case run := <-finished:
+			// Events published before the run returned are all queued
+			for len(events) > 0 {
+				d.handleEvent(<-events)
+			}
+			cancelRun()
+			cancelRun = nil
+			recordRun(cmd, run.entry, run.err)
+			if quitting {
+				return nil
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+func init() {
+	tuiCommand.Flags().StringVar(&workspaceFolder, "workspace-folder", "", "workspace folder path (defaults to git root)")
+	rootCmd.AddCommand(tuiCommand)
+}
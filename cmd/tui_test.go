@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+)
+
+var testANSI = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func newTestDashboard() *dashboard {
+	tasks := []config.Task{{Label: "build"}, {Label: "test", DependsOn: "build"}, {Label: "lint"}}
+	d := newDashboard(tasks, "tasks.json")
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d
+}
+
+func plainLines(lines []string) string {
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(testANSI.ReplaceAllString(line, ""), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDashboard_Events(t *testing.T) {
+	d := newTestDashboard()
+	start := d.now()
+	exitCode, failed := 0, 2
+
+	d.runStarted("test")
+	for _, event := range []executor.Event{
+		{Type: executor.EventPlan, Tasks: []string{"build", "test"}},
+		{Type: executor.EventTaskStarted, Task: "build", Time: start},
+		{Type: executor.EventOutput, Task: "build", Stream: "stdout", Line: "\x1b[32mcompiled\x1b[0m\tok"},
+		{Type: executor.EventOutput, Task: "build", Stream: "stdout", Line: "50%\r100%"},
+		{Type: executor.EventTaskFinished, Task: "build", ExitCode: &exitCode, Duration: 1500 * time.Millisecond},
+		{Type: executor.EventTaskStarted, Task: "test", Time: start.Add(-2 * time.Second)},
+	} {
+		d.handleEvent(event)
+	}
+
+	expected := ` tasks.json  running test 0s
+✓ build      1.5s│ build: passed (2 lines)
+● test         2s│ compiled    ok
+  lint           │ 100%
+                 │
+enter run  r rerun  c cancel  / search  n/N next/prev  PgUp/PgDn scroll  q quit`
+	if got := plainLines(d.render(80, 6)); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+
+	d.handleEvent(executor.Event{Type: executor.EventDiagnostic, Task: "test", Diagnostic: &executor.Diagnostic{}})
+	d.handleEvent(executor.Event{Type: executor.EventTaskFinished, Task: "test", ExitCode: &failed, Duration: time.Second})
+	d.handleEvent(executor.Event{Type: executor.EventRunFinished, Status: "failed", Duration: 2500 * time.Millisecond})
+	d.selectTask(1)
+
+	lines := d.render(80, 6)
+	if got := plainLines(lines[:3]); !strings.Contains(got, "test failed in 2.5s") || !strings.Contains(got, "test: failed (exit 2, 1 problem, 0 lines)") {
+		t.Errorf("expected the failed run in the header and pane, got\n%s", got)
+	}
+	if d.running != "" || d.tasks[1].status != statusFailed {
+		t.Errorf("expected the run to be over, got running %q, status %q", d.running, d.tasks[1].status)
+	}
+}
+
+func TestDashboard_PendingTasksAfterCancel(t *testing.T) {
+	d := newTestDashboard()
+	d.runStarted("test")
+	d.handleEvent(executor.Event{Type: executor.EventPlan, Tasks: []string{"build", "test"}})
+	d.handleEvent(executor.Event{Type: executor.EventRunFinished, Status: "cancelled"})
+
+	if d.tasks[0].status != statusCancelled || d.tasks[2].status != "" {
+		t.Errorf("expected only the planned tasks to be cancelled, got %q and %q", d.tasks[0].status, d.tasks[2].status)
+	}
+}
+
+func TestDashboard_Keys(t *testing.T) {
+	d := newTestDashboard()
+
+	if action, _ := d.handleKey(pickerKey{r: 'r'}); action != actionNone || d.message == "" {
+		t.Errorf("expected rerun to need an earlier run, got %v, %q", action, d.message)
+	}
+	if action, _ := d.handleKey(pickerKey{r: 'c'}); action != actionNone {
+		t.Errorf("expected nothing to cancel, got %v", action)
+	}
+
+	d.handleKey(pickerKey{name: "down"})
+	action, task := d.handleKey(pickerKey{name: "enter"})
+	if action != actionStart || task.Label != "test" {
+		t.Fatalf("expected test to start, got %v, %v", action, task)
+	}
+	d.runStarted(task.Label)
+
+	if action, _ := d.handleKey(pickerKey{r: 's'}); action != actionNone || !strings.Contains(d.message, "test is running") {
+		t.Errorf("expected a second run to be refused, got %v, %q", action, d.message)
+	}
+	if action, _ := d.handleKey(pickerKey{r: 'c'}); action != actionCancel {
+		t.Errorf("expected cancel, got %v", action)
+	}
+	d.handleEvent(executor.Event{Type: executor.EventRunFinished, Status: "cancelled"})
+
+	d.handleKey(pickerKey{r: 'j'})
+	if action, task := d.handleKey(pickerKey{r: 'r'}); action != actionStart || task.Label != "test" {
+		t.Errorf("expected rerun to start test again, got %v, %v", action, task)
+	}
+	if action, _ := d.handleKey(pickerKey{r: 'q'}); action != actionQuit {
+		t.Errorf("expected quit, got %v", action)
+	}
+}
+
+func TestDashboard_ScrollAndSearch(t *testing.T) {
+	d := newTestDashboard()
+	for i := 0; i < 30; i++ {
+		line := "step"
+		if i%10 == 3 {
+			line = "Error here"
+		}
+		d.handleEvent(executor.Event{Type: executor.EventOutput, Task: "build", Stream: "stdout", Line: line})
+	}
+	d.render(80, 7)
+	if d.pageSize != 4 {
+		t.Fatalf("expected 4 output lines per page, got %d", d.pageSize)
+	}
+
+	d.handleKey(pickerKey{name: "pageup"})
+	if d.scroll != 4 {
+		t.Errorf("expected to scroll up a page, got %d", d.scroll)
+	}
+	d.handleKey(pickerKey{r: 'g'})
+	if d.scroll != 26 {
+		t.Errorf("expected to scroll to the start, got %d", d.scroll)
+	}
+	d.handleKey(pickerKey{r: 'G'})
+	if d.scroll != 0 {
+		t.Errorf("expected to follow the end again, got %d", d.scroll)
+	}
+
+	for _, key := range parseKeys([]byte("/error\r")) {
+		d.handleKey(key)
+	}
+	if d.searching || d.match != 23 {
+		t.Fatalf("expected the last match to be selected, got line %d", d.match)
+	}
+	d.handleKey(pickerKey{r: 'N'})
+	if d.match != 13 || d.scroll != 13 {
+		t.Errorf("expected the previous match at the top of the pane, got line %d, scroll %d", d.match, d.scroll)
+	}
+	d.handleKey(pickerKey{r: 'n'})
+	d.handleKey(pickerKey{r: 'n'})
+	if d.match != 23 || !strings.Contains(d.message, "No more matches") {
+		t.Errorf("expected to stay on the last match, got line %d, %q", d.match, d.message)
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	if got := highlight("an Error and an error", "error"); got != "an \x1b[4mError\x1b[24m and an \x1b[4merror\x1b[24m" {
+		t.Errorf("unexpected highlight %q", got)
+	}
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
 	"github.com/garaemon/tasks-json-cli/internal/discovery"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
 	"github.com/spf13/cobra"
 )
 
@@ -165,18 +167,10 @@ func validateTasks(tasks []config.Task, result *ValidationResult) {
 		}
 		
 		// Validate task type
-		validTypes := []string{"shell", "process", "npm", "typescript"}
-		isValidType := false
-		for _, validType := range validTypes {
-			if task.Type == validType {
-				isValidType = true
-				break
-			}
-		}
-		if !isValidType {
+		if !executor.IsSupportedType(task.Type) {
 			result.Warnings = append(result.Warnings, ValidationError{
 				Type:      "unknown_type",
-				Message:   fmt.Sprintf("unknown task type '%s', supported types: %v", task.Type, validTypes),
+				Message:   fmt.Sprintf("unknown task type '%s', supported types: %v (custom types need a %s<type> executable on PATH)", task.Type, executor.SupportedTypes(), executor.PluginPrefix),
 				TaskLabel: task.Label,
 			})
 		}
@@ -201,6 +195,89 @@ func validateTasks(tasks []config.Task, result *ValidationResult) {
 			})
 		}
 		
+		if (task.Type == "go" || task.Type == "cargo") && task.Command == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Type:      "missing_command",
+				Message:   fmt.Sprintf("%s task requires 'command' field", task.Type),
+				TaskLabel: task.Label,
+			})
+		}
+		
+		if (task.Type == "gulp" || task.Type == "grunt") && task.TaskName == "" {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Type:      "missing_task",
+				Message:   fmt.Sprintf("%s task requires 'task' field", task.Type),
+				TaskLabel: task.Label,
+			})
+		}
+		
+		// Validate problem matchers
+		for _, matcher := range problemMatcherItems(task.ProblemMatcher) {
+			_, err := executor.ParseProblemMatchers(matcher)
+			var unsupported *executor.UnsupportedProblemMatcherError
+			if errors.As(err, &unsupported) {
+				result.Warnings = append(result.Warnings, ValidationError{
+					Type:      "unsupported_problem_matcher",
+					Message:   fmt.Sprintf("%s; the problem matcher will be ignored", err),
+					TaskLabel: task.Label,
+				})
+			} else if err != nil {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Type:      "invalid_problem_matcher",
+					Message:   err.Error(),
+					TaskLabel: task.Label,
+				})
+			}
+		}
+		for _, name := range problemMatcherNames(task.ProblemMatcher) {
+			if !executor.IsKnownProblemMatcher(name) {
+				result.Warnings = append(result.Warnings, ValidationError{
+					Type:      "unknown_problem_matcher",
+					Message:   fmt.Sprintf("problem matcher '%s' is not built in and will be ignored", name),
+					TaskLabel: task.Label,
+				})
+			}
+		}
+		
+		// Validate the x-watch extension
+		if task.Watch != nil {
+			validateWatchConfig(&task, result)
+		}
+		
+		// Validate the x-inputs and x-outputs globs
+		for _, pattern := range task.Inputs {
+			if !discovery.ValidatePattern(pattern) {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Type:      "invalid_inputs",
+					Message:   fmt.Sprintf("x-inputs pattern '%s' is not a valid glob", pattern),
+					TaskLabel: task.Label,
+				})
+			}
+		}
+		for _, pattern := range task.Outputs {
+			if !discovery.ValidatePattern(pattern) {
+				result.Valid = false
+				result.Errors = append(result.Errors, ValidationError{
+					Type:      "invalid_outputs",
+					Message:   fmt.Sprintf("x-outputs pattern '%s' is not a valid glob", pattern),
+					TaskLabel: task.Label,
+				})
+			}
+		}
+		
+		// A cached task without inputs is restored even when its sources change
+		if task.Cache && len(task.Inputs) == 0 {
+			result.Warnings = append(result.Warnings, ValidationError{
+				Type:      "cache_without_inputs",
+				Message:   "task has \"x-cache\": true but no x-inputs, so changes to its sources are not noticed",
+				TaskLabel: task.Label,
+			})
+		}
+		
 		// Validate working directory if specified
 		if task.Options != nil && task.Options.Cwd != "" {
 			// Only warn if it's an absolute path that doesn't exist
@@ -239,6 +316,75 @@ func validateTasks(tasks []config.Task, result *ValidationResult) {
 	}
 }
 
+// validateWatchConfig checks the x-watch block of a task.
+func validateWatchConfig(task *config.Task, result *ValidationResult) {
+	if _, err := task.Watch.GetDelay(); err != nil {
+		result.Valid = false
+		result.Errors = append(result.Errors, ValidationError{
+			Type:      "invalid_watch_config",
+			Message:   fmt.Sprintf("x-watch delay '%v' is not a duration (e.g. \"300ms\" or 300)", task.Watch.Delay),
+			TaskLabel: task.Label,
+		})
+	}
+	
+	patterns := append(append([]string{}, task.Watch.Include...), task.Watch.Exclude...)
+	for _, pattern := range patterns {
+		if !discovery.ValidatePattern(pattern) {
+			result.Valid = false
+			result.Errors = append(result.Errors, ValidationError{
+				Type:      "invalid_watch_config",
+				Message:   fmt.Sprintf("x-watch pattern '%s' is not a valid glob", pattern),
+				TaskLabel: task.Label,
+			})
+		}
+	}
+	
+	if definition, ok := task.Definition["x-watch"].(map[string]interface{}); ok {
+		for key := range definition {
+			known := false
+			for _, watchKey := range config.WatchConfigKeys {
+				if key == watchKey {
+					known = true
+					break
+				}
+			}
+			if !known {
+				result.Warnings = append(result.Warnings, ValidationError{
+					Type:      "unknown_watch_option",
+					Message:   fmt.Sprintf("unknown x-watch option '%s', supported options: %v", key, config.WatchConfigKeys),
+					TaskLabel: task.Label,
+				})
+			}
+		}
+	}
+}
+
+// problemMatcherItems returns the entries of a "problemMatcher" array, or the
+// value itself if it is not an array.
+func problemMatcherItems(problemMatcher interface{}) []interface{} {
+	if items, ok := problemMatcher.([]interface{}); ok {
+		return items
+	}
+	return []interface{}{problemMatcher}
+}
+
+// problemMatcherNames returns the named matchers referenced by a problemMatcher value.
+func problemMatcherNames(problemMatcher interface{}) []string {
+	switch v := problemMatcher.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var names []string
+		for _, item := range v {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
 func printValidationResult(result ValidationResult) {
 	if result.Valid && len(result.Warnings) == 0 {
 		fmt.Printf("✓ %s is valid\n", result.Path)
@@ -3,6 +3,8 @@ package cmd
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -367,4 +369,130 @@ func TestGetDependsOnAsStringSlice(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestValidateTasksFile_PluginType(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pluginDir := t.TempDir()
+	pluginPath := filepath.Join(pluginDir, "tasks-json-cli-type-bazel")
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", pluginDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	tasksFile := filepath.Join(tmpDir, "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "app", "type": "bazel", "target": "//app:all"},
+			{"label": "other", "type": "gradle"}
+		]
+	}`
+	if err := os.WriteFile(tasksFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := validateTasksFile(tasksFile)
+	if len(result.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %d: %v", len(result.Warnings), result.Warnings)
+	}
+	if result.Warnings[0].TaskLabel != "other" || result.Warnings[0].Type != "unknown_type" {
+		t.Errorf("expected unknown_type warning for 'other', got %v", result.Warnings[0])
+	}
+}
+
+func TestValidateTasksFile_ProblemMatchers(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksFile := filepath.Join(tmpDir, "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "tsc", "type": "typescript", "problemMatcher": "$tsc"},
+			{"label": "lint", "type": "shell", "command": "eslint .", "problemMatcher": ["$eslint-stylish"]},
+			{"label": "broken", "type": "shell", "command": "make", "problemMatcher": {"pattern": {"regexp": "("}}},
+			{"label": "eslint", "type": "shell", "command": "eslint .", "problemMatcher": {"base": "$eslint-stylish"}},
+			{"label": "compact", "type": "shell", "command": "eslint .", "problemMatcher": {"pattern": "$eslint-compact"}},
+			{"label": "lookahead", "type": "shell", "command": "make", "problemMatcher": [{"pattern": {"regexp": "^(.*):(\\d+)(?=:)"}}]}
+		]
+	}`
+	if err := os.WriteFile(tasksFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := validateTasksFile(tasksFile)
+	if result.Valid {
+		t.Error("expected invalid problem matcher to fail validation")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].TaskLabel != "broken" || result.Errors[0].Type != "invalid_problem_matcher" {
+		t.Errorf("expected invalid_problem_matcher error for 'broken', got %v", result.Errors)
+	}
+	if len(result.Warnings) != 4 || result.Warnings[0].TaskLabel != "lint" || result.Warnings[0].Type != "unknown_problem_matcher" {
+		t.Fatalf("expected unknown_problem_matcher warning for 'lint', got %v", result.Warnings)
+	}
+	for i, label := range []string{"eslint", "compact", "lookahead"} {
+		if warning := result.Warnings[i+1]; warning.TaskLabel != label || warning.Type != "unsupported_problem_matcher" {
+			t.Errorf("expected unsupported_problem_matcher warning for '%s', got %v", label, warning)
+		}
+	}
+}
+
+func TestValidateTasksFile_WatchConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksFile := filepath.Join(tmpDir, "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "ok", "type": "shell", "command": "go build", "x-watch": {"include": ["**/*.go"], "delay": "300ms", "restart": true, "server": false}},
+			{"label": "bad-delay", "type": "shell", "command": "make", "x-watch": {"delay": "soon"}},
+			{"label": "negative-delay", "type": "shell", "command": "make", "x-watch": {"delay": "-1s"}},
+			{"label": "bad-glob", "type": "shell", "command": "make", "x-watch": {"exclude": ["src/[a-"]}},
+			{"label": "typo", "type": "shell", "command": "make", "x-watch": {"exlude": ["dist"]}}
+		]
+	}`
+	if err := os.WriteFile(tasksFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := validateTasksFile(tasksFile)
+	if result.Valid {
+		t.Error("expected invalid x-watch blocks to fail validation")
+	}
+
+	var errorLabels []string
+	for _, e := range result.Errors {
+		if e.Type != "invalid_watch_config" {
+			t.Errorf("unexpected error: %v", e)
+		}
+		errorLabels = append(errorLabels, e.TaskLabel)
+	}
+	if strings.Join(errorLabels, ",") != "bad-delay,negative-delay,bad-glob" {
+		t.Errorf("expected errors for bad-delay, negative-delay and bad-glob, got %v", result.Errors)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0].TaskLabel != "typo" || result.Warnings[0].Type != "unknown_watch_option" {
+		t.Errorf("expected unknown_watch_option warning for 'typo', got %v", result.Warnings)
+	}
+}
+
+func TestValidateTasksFile_Inputs(t *testing.T) {
+	tmpDir := t.TempDir()
+	tasksFile := filepath.Join(tmpDir, "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "ok", "type": "shell", "command": "go build", "x-inputs": ["**/*.go", "go.mod"]},
+			{"label": "bad", "type": "shell", "command": "make", "x-inputs": ["src/[a-"]}
+		]
+	}`
+	if err := os.WriteFile(tasksFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result := validateTasksFile(tasksFile)
+	if result.Valid {
+		t.Error("expected an invalid x-inputs glob to fail validation")
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Type != "invalid_inputs" || result.Errors[0].TaskLabel != "bad" {
+		t.Errorf("expected one invalid_inputs error for 'bad', got %+v", result.Errors)
+	}
+}
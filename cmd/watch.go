@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/garaemon/tasks-json-cli/internal/config"
 	"github.com/garaemon/tasks-json-cli/internal/discovery"
 	"github.com/garaemon/tasks-json-cli/internal/executor"
 	"github.com/spf13/cobra"
@@ -17,38 +22,51 @@ import (
 
 var watchPaths []string
 var watchExtensions []string
+var watchInclude []string
 var watchExclude []string
+var watchNoIgnore bool
+var watchRemovals bool
+var watchPoll bool
+var watchPollInterval time.Duration
 var watchDelay time.Duration
+var watchQueue bool
+var watchOn []string
+var watchParallel bool
+var watchRestart bool
+var watchStopTimeout time.Duration
 
 var watchCommand = &cobra.Command{
-	Use:   "watch <task-name>",
+	Use:   "watch [task-name...]",
 	Short: "Watch files and auto-execute task",
-	Long:  `Watch for file changes and automatically execute the specified task when changes are detected.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Watch for file changes and automatically execute the specified task, including its
+dependencies, when changes are detected. A change during a run cancels the run and starts
+it again; with --queue the new run starts after the current one has finished. Without a
+task name on a terminal, a picker lists the tasks to choose from.
+
+Background tasks (isBackground, or --restart) are treated as servers: on a change the
+running instance is stopped gracefully before a new one starts, and a crashed server
+is restarted with an increasing delay.
+
+Several tasks can be watched at once, also by naming them with a glob like 'test:*'.
+Each one reacts to the files matched by its x-watch include patterns, or by the
+patterns routed to it with --on:
+
+  tasks-json-cli watch --on '*.go=lint-go' --on '*.scss=build-css' --on '*.proto=gen'`,
+	Args:  cobra.ArbitraryArgs,
+	ValidArgsFunction: completeTaskLabels(0),
 	RunE:  executeWatchCommand,
 	SilenceUsage: true,
 }
 
 func executeWatchCommand(cmd *cobra.Command, args []string) error {
-	taskName := args[0]
-
-	// Determine workspace folder
-	var workspaceDir string
-	if workspaceFolder != "" {
-		workspaceDir = workspaceFolder
-	} else {
-		// Default to git root
-		currentDir, err := os.Getwd()
-		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
-		}
-		gitRoot, err := discovery.FindGitRoot(currentDir)
-		if err != nil {
-			// Fall back to current directory if git root not found
-			workspaceDir = currentDir
-		} else {
-			workspaceDir = gitRoot
-		}
+	pick := len(args) == 0 && len(watchOn) == 0
+	if pick && !canPickTask() {
+		return fmt.Errorf("specify a task to watch or at least one --on <pattern>=<task> route")
+	}
+
+	workspaceDir, err := resolveWorkspaceDir()
+	if err != nil {
+		return err
 	}
 
 	if verbose {
@@ -64,133 +82,392 @@ func executeWatchCommand(cmd *cobra.Command, args []string) error {
 		fmt.Printf("Using tasks file: %s\n", tasksFilePath)
 	}
 
-	tasks, err := config.LoadTasks(tasksFilePath)
+	// The picker lists every task, otherwise only the watched ones are needed
+	var names []string
+	if !pick {
+		names = append(append(names, args...), watchRouteTasks()...)
+	}
+	tasks, err := loadTasksFor(tasksFilePath, workspaceDir, names)
 	if err != nil {
-		return fmt.Errorf("failed to load tasks: %w", err)
+		return err
 	}
 
-	var targetTask *config.Task
-	for i := range tasks {
-		if tasks[i].Label == taskName {
-			targetTask = &tasks[i]
-			break
+	if pick {
+		task, err := pickTask(tasks, workspaceDir, "watch")
+		if err != nil {
+			return err
 		}
+		args = []string{task.Label}
+		names = append(append(names, args...), watchRouteTasks()...)
 	}
 
-	if targetTask == nil {
-		return fmt.Errorf("task '%s' not found", taskName)
-	}
-
-	// Set up file watcher
-	watcher, err := fsnotify.NewWatcher()
+	targets, err := buildWatchTargets(cmd, tasks, args, workspaceDir)
 	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
+		return err
 	}
-	defer func() { _ = watcher.Close() }()
 
 	// Set default watch paths if none specified
 	if len(watchPaths) == 0 {
-		watchPaths = []string{workspaceDir}
+		watchPaths = defaultWatchPaths(targets, workspaceDir)
 	}
 
-	// Add watch paths
-	for _, path := range watchPaths {
-		var fullPath string
-		if filepath.IsAbs(path) {
-			fullPath = path
-		} else {
-			fullPath = filepath.Join(workspaceDir, path)
-		}
+	poll := watchPoll
+	for _, target := range targets {
+		poll = poll || target.poll
+	}
 
-		err = addWatchPath(watcher, fullPath)
-		if err != nil {
-			return fmt.Errorf("failed to watch path %s: %w", fullPath, err)
-		}
-		if verbose {
-			fmt.Printf("Watching: %s\n", fullPath)
-		}
+	filter := newDirFilter(targets, workspaceDir)
+
+	watcher, err := startFileWatcher(filter, workspaceDir, poll)
+	if err != nil {
+		return err
 	}
+	defer func() { _ = watcher.Close() }()
+
+	configFiles := watchedConfigFiles(tasksFilePath)
+	addConfigDirs(watcher, configFiles)
 
+	labels := make([]string, len(targets))
+	for i, target := range targets {
+		labels[i] = target.label
+	}
 	if !quiet {
-		fmt.Printf("Watching for changes... (task: %s)\n", taskName)
+		if len(labels) == 1 {
+			fmt.Printf("Watching for changes... (task: %s)\n", labels[0])
+		} else {
+			fmt.Printf("Watching for changes... (tasks: %s)\n", strings.Join(labels, ", "))
+		}
 		fmt.Println("Press Ctrl+C to stop")
 	}
 
-	// Channel for debouncing file events
-	debounceTimer := time.NewTimer(0)
-	debounceTimer.Stop()
+	var status io.Writer = os.Stdout
+	if quiet {
+		status = nil
+	}
 
-	executeTask := func() {
-		if !quiet {
-			fmt.Printf("Executing task: %s\n", targetTask.Label)
-		}
-		err := executor.RunTask(targetTask, workspaceDir, file)
-		if err != nil {
-			log.Printf("Task execution failed: %v", err)
-		}
+	// Unless --parallel is given, runs of different tasks wait for each other
+	var slot chan struct{}
+	if !watchParallel {
+		slot = make(chan struct{}, 1)
+	}
+
+	cache, err := openCache(workspaceDir)
+	if err != nil {
+		return err
+	}
+
+	var tasksMu sync.Mutex
+	for _, target := range targets {
+		label := target.label
+		target.runner = newWatchRunner(label, target.queue, status, func(ctx context.Context, ready func()) error {
+			tasksMu.Lock()
+			allTasks := tasks
+			tasksMu.Unlock()
+			task, err := lookupTask(allTasks, label)
+			if err != nil {
+				return err
+			}
+			return executor.Run(ctx, task, allTasks, executor.RunOptions{
+				WorkspaceDir: workspaceDir,
+				File:         file,
+				StopTimeout:  watchStopTimeout,
+				OnReady:      ready,
+				Cache:        cache,
+			})
+		})
+		target.runner.server = target.server
+		target.runner.slot = slot
 	}
 
+	// Stop the current runs before exiting on Ctrl+C
+	interrupted, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
 	// Watch for events
 	for {
 		select {
-		case event, ok := <-watcher.Events:
+		case <-interrupted.Done():
+			for _, target := range targets {
+				target.stop()
+			}
+			return nil
+
+		case event, ok := <-watcher.events():
 			if !ok {
 				return nil
 			}
 
-			// Check if we should handle this event
-			if !shouldHandleEvent(event) {
-				continue
+			var changed []*watchTarget
+			if isConfigFileEvent(configFiles, event) {
+				reloaded, err := loadTasksFor(tasksFilePath, workspaceDir, names)
+				if err == nil {
+					err = reloadWatchTargets(cmd, reloaded, targets, workspaceDir)
+				}
+				if err != nil {
+					log.Printf("Keeping previous task definitions: %v", err)
+					continue
+				}
+				tasksMu.Lock()
+				tasks = reloaded
+				tasksMu.Unlock()
+
+				// Register the x-watch paths and directories the new excludes let in
+				filter.excludes = newDirFilter(targets, workspaceDir).excludes
+				if !cmd.Flags().Changed("path") {
+					watchPaths = defaultWatchPaths(targets, workspaceDir)
+				}
+				if err := addWatchPaths(watcher, filter, workspaceDir); err != nil {
+					log.Printf("Watch error: %v", err)
+				}
+				if !quiet {
+					fmt.Printf("Reloaded %s\n", filepath.Base(event.Name))
+				}
+				changed = targets
+			} else if isNewDirectory(event) {
+				// Directories created after startup are watched as well
+				if filter.skipDir(event.Name) {
+					continue
+				}
+				if err := addWatchPath(watcher, filter, event.Name); err != nil {
+					if !needsPolling(watcher, err) {
+						log.Printf("Watch error: %v", err)
+						continue
+					}
+					if watcher, err = fallBackToPolling(watcher, filter, workspaceDir, configFiles, err); err != nil {
+						return err
+					}
+				}
+				if verbose {
+					fmt.Printf("Watching: %s\n", event.Name)
+				}
+				for _, target := range targets {
+					if containsWatchedFile(target.filter, event.Name) {
+						changed = append(changed, target)
+					}
+				}
+			} else {
+				for _, target := range targets {
+					if shouldHandleEvent(target.filter, event) {
+						changed = append(changed, target)
+					}
+				}
+				if verbose && len(changed) > 0 {
+					fmt.Printf("File changed: %s\n", event.Name)
+				}
 			}
 
-			if verbose {
-				fmt.Printf("File changed: %s\n", event.Name)
+			for _, target := range changed {
+				target.debounce()
 			}
 
-			// Debounce events - reset timer
-			debounceTimer.Stop()
-			debounceTimer = time.AfterFunc(watchDelay, executeTask)
-
-		case err, ok := <-watcher.Errors:
+		case err, ok := <-watcher.errors():
 			if !ok {
 				return nil
 			}
-			log.Printf("Watch error: %v", err)
+			if !needsPolling(watcher, err) {
+				log.Printf("Watch error: %v", err)
+				continue
+			}
+			if watcher, err = fallBackToPolling(watcher, filter, workspaceDir, configFiles, err); err != nil {
+				return err
+			}
+			// Changes may have been lost, so run every task again
+			for _, target := range targets {
+				target.debounce()
+			}
 		}
 	}
 }
 
-func addWatchPath(watcher *fsnotify.Watcher, path string) error {
-	return filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// needsPolling reports whether a notification watcher can no longer be relied
+// upon: its event queue overflowed or the OS ran out of watches.
+func needsPolling(watcher fileWatcher, err error) bool {
+	if _, polling := watcher.(*pollWatcher); polling {
+		return false
+	}
+	return errors.Is(err, fsnotify.ErrEventOverflow) || isWatchLimitError(err)
+}
+
+// fallBackToPolling replaces a notification watcher that failed while
+// watching with a pollWatcher for the same paths.
+func fallBackToPolling(watcher fileWatcher, filter *watchFilter, workspaceDir string, configFiles []string, reason error) (fileWatcher, error) {
+	log.Printf("File system notifications failed (%v), polling every %s instead", reason, watchPollInterval)
+	_ = watcher.Close()
+
+	poller := newPollWatcher(watchPollInterval)
+	if err := addWatchPaths(poller, filter, workspaceDir); err != nil {
+		_ = poller.Close()
+		return nil, err
+	}
+	addConfigDirs(poller, configFiles)
+	return poller, nil
+}
+
+// addConfigDirs watches the folder of tasks.json so that edits replacing the
+// file are seen too.
+func addConfigDirs(watcher watchAdder, configFiles []string) {
+	for _, configFile := range configFiles {
+		if err := watcher.Add(filepath.Dir(configFile)); err != nil && verbose {
+			fmt.Printf("Not watching %s: %v\n", configFile, err)
+		}
+	}
+}
+
+// startFileWatcher sets up a watcher for all watch paths. It polls when --poll
+// is given, and falls back to polling when the OS runs out of file watches.
+func startFileWatcher(filter *watchFilter, workspaceDir string, poll bool) (fileWatcher, error) {
+	if poll {
+		watcher := newPollWatcher(watchPollInterval)
+		if err := addWatchPaths(watcher, filter, workspaceDir); err != nil {
+			_ = watcher.Close()
+			return nil, err
 		}
+		return watcher, nil
+	}
 
-		// Skip directories that should be excluded
+	notify, err := fsnotify.NewWatcher()
+	if err == nil {
+		watcher := notifyWatcher{notify}
+		err = addWatchPaths(watcher, filter, workspaceDir)
+		if err == nil {
+			return watcher, nil
+		}
+		_ = watcher.Close()
+		if !isWatchLimitError(err) {
+			return nil, err
+		}
+	} else if !isWatchLimitError(err) {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	log.Printf("File system notifications unavailable (%v), polling every %s instead", err, watchPollInterval)
+	watcher := newPollWatcher(watchPollInterval)
+	if err := addWatchPaths(watcher, filter, workspaceDir); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	return watcher, nil
+}
+
+// addWatchPaths adds every watch path, resolved against the workspace folder.
+func addWatchPaths(watcher watchAdder, filter *watchFilter, workspaceDir string) error {
+	for _, path := range watchPaths {
+		var fullPath string
+		if filepath.IsAbs(path) {
+			fullPath = path
+		} else {
+			fullPath = filepath.Join(workspaceDir, path)
+		}
+
+		if err := addWatchPath(watcher, filter, fullPath); err != nil {
+			return fmt.Errorf("failed to watch path %s: %w", fullPath, err)
+		}
+		if verbose {
+			fmt.Printf("Watching: %s\n", fullPath)
+		}
+	}
+	return nil
+}
+
+// watchedConfigFiles returns the files whose changes reload the task definitions.
+func watchedConfigFiles(tasksFilePath string) []string {
+	absPath, err := filepath.Abs(tasksFilePath)
+	if err != nil {
+		absPath = tasksFilePath
+	}
+	return []string{absPath, discovery.FindSettingsFile(absPath)}
+}
+
+func isConfigFileEvent(configFiles []string, event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+		return false
+	}
+	name, err := filepath.Abs(event.Name)
+	if err != nil {
+		return false
+	}
+	for _, configFile := range configFiles {
+		if name == configFile {
+			return true
+		}
+	}
+	return false
+}
+
+func isNewDirectory(event fsnotify.Event) bool {
+	if event.Op&fsnotify.Create == 0 {
+		return false
+	}
+	info, err := os.Stat(event.Name)
+	return err == nil && info.IsDir()
+}
+
+// containsWatchedFile reports whether a directory holds a file that watch
+// reacts to, e.g. when a folder is moved into the workspace.
+func containsWatchedFile(filter *watchFilter, dir string) bool {
+	found := false
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found {
+			return filepath.SkipAll
+		}
 		if info.IsDir() {
-			for _, exclude := range watchExclude {
-				if strings.Contains(walkPath, exclude) {
-					return filepath.SkipDir
-				}
+			if path != dir && filter.skipDir(path) {
+				return filepath.SkipDir
 			}
-			return watcher.Add(walkPath)
+			return nil
 		}
-
+		found = filter.acceptsFile(path)
 		return nil
 	})
+	return found
 }
 
-func shouldHandleEvent(event fsnotify.Event) bool {
-	// Only handle write and create events
-	if event.Op&fsnotify.Write == 0 && event.Op&fsnotify.Create == 0 {
+// watchFilter decides which paths watch reacts to. Include and exclude
+// patterns are gitignore-style globs relative to the workspace folder.
+type watchFilter struct {
+	root       string
+	extensions []string
+	includes   []string
+	excludes   []string
+	// paths restricts the filter to files below these directories
+	paths      []string
+	removals   bool
+	ignore     *discovery.IgnoreMatcher
+}
+
+func newWatchFilter(root string) *watchFilter {
+	filter := &watchFilter{
+		root:       root,
+		extensions: watchExtensions,
+		includes:   watchInclude,
+		excludes:   watchExclude,
+		removals:   watchRemovals,
+	}
+	if !watchNoIgnore {
+		filter.ignore = discovery.NewIgnoreMatcher(root)
+	}
+	return filter
+}
+
+// skipDir reports whether a directory and everything below it is not watched.
+func (f *watchFilter) skipDir(path string) bool {
+	rel, ok := discovery.RelativePath(f.root, path)
+	if !ok || rel == "" {
 		return false
 	}
+	if f.isExcluded(rel) {
+		return true
+	}
+	return f.ignore != nil && f.ignore.Match(path, true)
+}
 
+// acceptsFile reports whether a change to the file should trigger the task.
+func (f *watchFilter) acceptsFile(path string) bool {
 	// Check file extensions if specified
-	if len(watchExtensions) > 0 {
-		ext := filepath.Ext(event.Name)
+	if len(f.extensions) > 0 {
+		ext := filepath.Ext(path)
 		found := false
-		for _, allowedExt := range watchExtensions {
+		for _, allowedExt := range f.extensions {
 			if strings.EqualFold(ext, allowedExt) {
 				found = true
 				break
@@ -201,14 +478,82 @@ func shouldHandleEvent(event fsnotify.Event) bool {
 		}
 	}
 
-	// Check if file should be excluded
-	for _, exclude := range watchExclude {
-		if strings.Contains(event.Name, exclude) {
+	if len(f.paths) > 0 {
+		found := false
+		for _, dir := range f.paths {
+			if _, ok := discovery.RelativePath(dir, path); ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	rel, ok := discovery.RelativePath(f.root, path)
+	if !ok {
+		// Explicitly watched paths outside the workspace are only filtered by extension
+		return true
+	}
+
+	if len(f.includes) > 0 {
+		found := false
+		for _, pattern := range f.includes {
+			if discovery.MatchPatternOrParent(pattern, rel) {
+				found = true
+				break
+			}
+		}
+		if !found {
 			return false
 		}
 	}
 
-	return true
+	if f.isExcluded(rel) {
+		return false
+	}
+	return f.ignore == nil || !f.ignore.Match(path, false)
+}
+
+func (f *watchFilter) isExcluded(rel string) bool {
+	for _, pattern := range f.excludes {
+		if discovery.MatchPatternOrParent(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+func addWatchPath(watcher watchAdder, filter *watchFilter, path string) error {
+	return filepath.Walk(path, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip excluded and ignored directories before they are registered
+		if info.IsDir() {
+			if walkPath != path && filter.skipDir(walkPath) {
+				return filepath.SkipDir
+			}
+			return watcher.Add(walkPath)
+		}
+
+		return nil
+	})
+}
+
+func shouldHandleEvent(filter *watchFilter, event fsnotify.Event) bool {
+	// Handle write and create events, and optionally removals and renames
+	ops := fsnotify.Write | fsnotify.Create
+	if filter.removals {
+		ops |= fsnotify.Remove | fsnotify.Rename
+	}
+	if event.Op&ops == 0 {
+		return false
+	}
+
+	return filter.acceptsFile(event.Name)
 }
 
 func init() {
@@ -216,7 +561,20 @@ func init() {
 	watchCommand.Flags().StringVar(&file, "file", "", "file path to replace ${file} variable")
 	watchCommand.Flags().StringSliceVar(&watchPaths, "path", []string{}, "paths to watch (defaults to workspace folder)")
 	watchCommand.Flags().StringSliceVar(&watchExtensions, "ext", []string{}, "file extensions to watch (e.g., .go,.js)")
-	watchCommand.Flags().StringSliceVar(&watchExclude, "exclude", []string{"node_modules", ".git", ".vscode"}, "paths to exclude from watching")
+	watchCommand.Flags().StringSliceVar(&watchInclude, "include", []string{}, "glob patterns of files to watch (e.g., 'src/**/*.go')")
+	watchCommand.Flags().StringSliceVar(&watchExclude, "exclude", []string{"node_modules", ".git", ".vscode"}, "glob patterns of paths to exclude from watching")
+	watchCommand.Flags().BoolVar(&watchNoIgnore, "no-ignore", false, "do not skip files matched by .gitignore, .ignore and .git/info/exclude")
 	watchCommand.Flags().DurationVar(&watchDelay, "delay", 500*time.Millisecond, "delay before executing task after file change")
+	watchCommand.Flags().BoolVar(&watchRemovals, "removals", false, "also run the task when files are removed or renamed")
+	watchCommand.Flags().BoolVar(&watchPoll, "poll", false, "detect changes by polling instead of file system notifications")
+	watchCommand.Flags().DurationVar(&watchPollInterval, "poll-interval", time.Second, "interval between scans in polling mode")
+	watchCommand.Flags().StringArrayVar(&watchOn, "on", []string{}, "run a task when files matching a glob change, as <pattern>=<task> (repeatable)")
+	watchCommand.Flags().BoolVar(&watchParallel, "parallel", false, "run different watched tasks concurrently instead of one at a time")
+	watchCommand.Flags().BoolVar(&watchRestart, "restart", false, "treat tasks as long-running servers that are restarted on changes and after crashes")
+	watchCommand.Flags().DurationVar(&watchStopTimeout, "stop-timeout", 5*time.Second, "how long a task gets to exit after an interrupt before it is killed")
+	watchCommand.Flags().BoolVar(&watchQueue, "queue", false, "queue changes during a run instead of cancelling the run")
+	watchCommand.Flags().BoolVar(&noCache, "no-cache", false, "run tasks with \"x-cache\" instead of restoring them from the cache")
+	watchCommand.Flags().BoolVar(&looseMatch, "loose", false, "match task names case-insensitively or by a unique prefix")
+	_ = watchCommand.RegisterFlagCompletionFunc("on", completeWatchRoute)
 	rootCmd.AddCommand(watchCommand)
 }
\ No newline at end of file
@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchAdder registers a directory with a watcher.
+type watchAdder interface {
+	Add(path string) error
+}
+
+// fileWatcher is the part of fsnotify.Watcher used by watch, so that a
+// polling implementation can stand in for it.
+type fileWatcher interface {
+	watchAdder
+	Close() error
+	events() <-chan fsnotify.Event
+	errors() <-chan error
+}
+
+// notifyWatcher adapts fsnotify.Watcher to fileWatcher.
+type notifyWatcher struct {
+	*fsnotify.Watcher
+}
+
+func (w notifyWatcher) events() <-chan fsnotify.Event { return w.Events }
+func (w notifyWatcher) errors() <-chan error          { return w.Errors }
+
+// isWatchLimitError reports whether err means the OS ran out of watches or
+// file descriptors, e.g. when fs.inotify.max_user_watches is exhausted.
+func isWatchLimitError(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
+// fileState is what the poller remembers about a directory entry.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// pollWatcher detects changes by scanning the watched directories at a fixed
+// interval and comparing modification times and sizes. It reports the same
+// events as fsnotify, and like fsnotify watches directories non-recursively.
+type pollWatcher struct {
+	interval time.Duration
+	eventCh  chan fsnotify.Event
+	errorCh  chan error
+	done     chan struct{}
+	stopped  sync.Once
+
+	mu   sync.Mutex
+	dirs map[string]map[string]fileState
+}
+
+func newPollWatcher(interval time.Duration) *pollWatcher {
+	w := &pollWatcher{
+		interval: interval,
+		eventCh:  make(chan fsnotify.Event, 256),
+		errorCh:  make(chan error, 16),
+		done:     make(chan struct{}),
+		dirs:     make(map[string]map[string]fileState),
+	}
+	go w.loop()
+	return w
+}
+
+func (w *pollWatcher) events() <-chan fsnotify.Event { return w.eventCh }
+func (w *pollWatcher) errors() <-chan error          { return w.errorCh }
+
+// Add starts polling a directory.
+func (w *pollWatcher) Add(path string) error {
+	entries, err := scanDir(path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.dirs[path]; !ok {
+		w.dirs[path] = entries
+	}
+	return nil
+}
+
+// Close stops polling.
+func (w *pollWatcher) Close() error {
+	w.stopped.Do(func() { close(w.done) })
+	return nil
+}
+
+func (w *pollWatcher) loop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			for _, event := range w.poll() {
+				select {
+				case w.eventCh <- event:
+				case <-w.done:
+					return
+				}
+			}
+		}
+	}
+}
+
+// poll rescans every watched directory and returns the changes since the
+// previous scan.
+func (w *pollWatcher) poll() []fsnotify.Event {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []fsnotify.Event
+	for dir, previous := range w.dirs {
+		current, err := scanDir(dir)
+		if err != nil {
+			// The directory itself is gone; fsnotify drops such watches too
+			delete(w.dirs, dir)
+			continue
+		}
+
+		for name, state := range current {
+			path := filepath.Join(dir, name)
+			old, ok := previous[name]
+			switch {
+			case !ok:
+				events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Create})
+			case !state.isDir && (!state.modTime.Equal(old.modTime) || state.size != old.size):
+				events = append(events, fsnotify.Event{Name: path, Op: fsnotify.Write})
+			}
+		}
+		for name := range previous {
+			if _, ok := current[name]; !ok {
+				events = append(events, fsnotify.Event{Name: filepath.Join(dir, name), Op: fsnotify.Remove})
+			}
+		}
+
+		w.dirs[dir] = current
+	}
+	return events
+}
+
+func scanDir(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between listing and stat
+			continue
+		}
+		states[entry.Name()] = fileState{modTime: info.ModTime(), size: info.Size(), isDir: entry.IsDir()}
+	}
+	return states, nil
+}
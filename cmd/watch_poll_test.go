@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestPollWatcher_Poll(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "main.go")
+	removed := filepath.Join(dir, "old.go")
+	for _, path := range []string{existing, removed} {
+		if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Poll manually instead of waiting for the ticker
+	watcher := newPollWatcher(time.Hour)
+	defer func() { _ = watcher.Close() }()
+	if err := watcher.Add(dir); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if events := watcher.poll(); len(events) != 0 {
+		t.Errorf("expected no events without changes, got %v", events)
+	}
+
+	if err := os.WriteFile(existing, []byte("package main\n\nfunc main() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(removed); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "pkg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, event := range watcher.poll() {
+		got = append(got, fmt.Sprintf("%s %s", event.Op, filepath.Base(event.Name)))
+	}
+	sort.Strings(got)
+	expected := []string{"CREATE new.go", "CREATE pkg", "REMOVE old.go", "WRITE main.go"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Errorf("expected events %v, got %v", expected, got)
+	}
+
+	if events := watcher.poll(); len(events) != 0 {
+		t.Errorf("expected changes to be reported once, got %v", events)
+	}
+}
+
+func TestPollWatcher_RemovedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := newPollWatcher(time.Hour)
+	defer func() { _ = watcher.Close() }()
+	for _, path := range []string{dir, sub} {
+		if err := watcher.Add(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Remove(sub); err != nil {
+		t.Fatal(err)
+	}
+	events := watcher.poll()
+	if len(events) != 1 || events[0].Op != fsnotify.Remove || events[0].Name != sub {
+		t.Errorf("expected a single remove event for %s, got %v", sub, events)
+	}
+	if _, ok := watcher.dirs[sub]; ok {
+		t.Error("expected removed directory to be dropped from the poll list")
+	}
+}
+
+func TestPollWatcher_DeliversEvents(t *testing.T) {
+	dir := t.TempDir()
+	watcher := newPollWatcher(20 * time.Millisecond)
+	defer func() { _ = watcher.Close() }()
+	if err := watcher.Add(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case event := <-watcher.events():
+		if event.Name != path || !shouldHandleEvent(newWatchFilter(dir), event) {
+			t.Errorf("unexpected event %v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected an event from the poller")
+	}
+}
+
+func TestIsWatchLimitError(t *testing.T) {
+	if !isWatchLimitError(fmt.Errorf("failed to watch path: %w", syscall.ENOSPC)) {
+		t.Error("expected ENOSPC to be a watch limit error")
+	}
+	if !isWatchLimitError(syscall.EMFILE) {
+		t.Error("expected EMFILE to be a watch limit error")
+	}
+	if isWatchLimitError(syscall.ENOENT) {
+		t.Error("expected ENOENT not to be a watch limit error")
+	}
+}
+
+func TestStartFileWatcher_Poll(t *testing.T) {
+	root := t.TempDir()
+	origPaths := watchPaths
+	watchPaths = []string{root}
+	defer func() { watchPaths = origPaths }()
+
+	watcher, err := startFileWatcher(newWatchFilter(root), root, true)
+	if err != nil {
+		t.Fatalf("startFileWatcher failed: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	if _, ok := watcher.(*pollWatcher); !ok {
+		t.Errorf("expected a polling watcher with --poll, got %T", watcher)
+	}
+}
+
+func TestFallBackToPolling(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	origPaths := watchPaths
+	watchPaths = []string{root}
+	defer func() { watchPaths = origPaths }()
+
+	watcher, err := startFileWatcher(newWatchFilter(root), root, false)
+	if err != nil {
+		t.Fatalf("startFileWatcher failed: %v", err)
+	}
+	if !needsPolling(watcher, fsnotify.ErrEventOverflow) || !needsPolling(watcher, syscall.ENOSPC) {
+		t.Error("expected an overflow or running out of watches to need polling")
+	}
+	if needsPolling(watcher, syscall.ENOENT) {
+		t.Error("expected other errors not to need polling")
+	}
+
+	watcher, err = fallBackToPolling(watcher, newWatchFilter(root), root, nil, fsnotify.ErrEventOverflow)
+	if err != nil {
+		t.Fatalf("fallBackToPolling failed: %v", err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	poller, ok := watcher.(*pollWatcher)
+	if !ok {
+		t.Fatalf("expected a polling watcher, got %T", watcher)
+	}
+	if _, ok := poller.dirs[filepath.Join(root, "src")]; !ok {
+		t.Errorf("expected the watch paths to be polled, got %v", poller.dirs)
+	}
+	if needsPolling(watcher, fsnotify.ErrEventOverflow) {
+		t.Error("expected a polling watcher not to fall back again")
+	}
+}
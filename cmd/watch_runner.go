@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Crash-loop backoff of servers: a server that fails within serverCrashWindow
+// of its start is restarted after serverRestartDelay, doubling with every
+// crash in a row up to serverMaxRestartDelay. After serverMaxCrashes crashes
+// in a row watch waits for the next change.
+const (
+	serverCrashWindow     = 5 * time.Second
+	serverRestartDelay    = time.Second
+	serverMaxRestartDelay = 30 * time.Second
+	serverMaxCrashes      = 5
+)
+
+// watchRunner executes a task on every change detected by watch. A change that
+// arrives while a run is in progress cancels that run and starts a new one, or,
+// in queue mode, starts one more run after the current one has finished. Queue
+// mode does not apply to servers.
+//
+// In server mode the task is expected to keep running. It is restarted when it
+// crashes, with an increasing delay if it crashes right after starting.
+type watchRunner struct {
+	label  string
+	run    func(ctx context.Context, ready func()) error
+	queue  bool
+	server bool
+	out    io.Writer
+	// slot, if set, is shared with other runners to run one task at a time.
+	// Servers never finish, so they run without it.
+	slot chan struct{}
+
+	mu      sync.Mutex
+	count   int
+	active  bool
+	pending bool
+	cancel  context.CancelFunc
+	idle    *sync.Cond
+	crashes int
+	retry   *time.Timer
+	// restartDelay is the initial crash restart delay, overridden by tests
+	restartDelay time.Duration
+}
+
+func newWatchRunner(label string, queue bool, out io.Writer, run func(ctx context.Context, ready func()) error) *watchRunner {
+	r := &watchRunner{label: label, run: run, queue: queue, out: out, restartDelay: serverRestartDelay}
+	r.idle = sync.NewCond(&r.mu)
+	return r
+}
+
+// trigger requests a run of the task after a change.
+func (r *watchRunner) trigger() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A change may well fix a crashing server, so start over
+	r.stopRetry()
+	r.crashes = 0
+
+	if !r.active {
+		r.start()
+		return
+	}
+
+	r.pending = true
+	// A server never finishes, so a queued change would never be run
+	if !r.queue || r.server {
+		r.cancel()
+	}
+}
+
+// restart starts a crashed server again.
+func (r *watchRunner) restart() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.retry = nil
+	if !r.active {
+		r.start()
+	}
+}
+
+// configure changes the settings of later runs after the task definitions
+// have been reloaded.
+func (r *watchRunner) configure(queue bool, server bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue = queue
+	r.server = server
+}
+
+// start launches a run. It must be called with r.mu held.
+func (r *watchRunner) start() {
+	r.active = true
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	server := r.server
+
+	go func() {
+		defer cancel()
+		if r.slot != nil && !server {
+			// Wait for runs of other tasks to finish first
+			select {
+			case r.slot <- struct{}{}:
+				defer func() { <-r.slot }()
+			case <-ctx.Done():
+				r.finish(false, 0)
+				return
+			}
+		}
+
+		r.mu.Lock()
+		r.count++
+		n := r.count
+		r.mu.Unlock()
+		r.printf("[watch] run %d started (task: %s)\n", n, r.label)
+
+		started := time.Now()
+		ready := func() {
+			r.printf("[watch] run %d ready after %.1fs\n", n, time.Since(started).Seconds())
+		}
+		err := r.run(ctx, ready)
+		elapsed := time.Since(started)
+
+		crashed := false
+		switch {
+		case errors.Is(err, context.Canceled):
+			r.printf("[watch] run %d cancelled after %.1fs\n", n, elapsed.Seconds())
+		case err != nil:
+			r.printf("[watch] run %d failed in %.1fs: %v\n", n, elapsed.Seconds(), err)
+			crashed = server
+		default:
+			r.printf("[watch] run %d finished in %.1fs\n", n, elapsed.Seconds())
+		}
+		r.finish(crashed, elapsed)
+	}()
+}
+
+// finish marks the current run as done and starts a pending one, or restarts
+// a crashed server.
+func (r *watchRunner) finish(crashed bool, elapsed time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = false
+	if r.pending {
+		r.pending = false
+		r.start()
+		return
+	}
+	if crashed {
+		r.scheduleRestart(elapsed)
+	}
+	r.idle.Broadcast()
+}
+
+// scheduleRestart restarts a crashed server after the backoff delay. It must
+// be called with r.mu held.
+func (r *watchRunner) scheduleRestart(elapsed time.Duration) {
+	if elapsed < serverCrashWindow {
+		r.crashes++
+	} else {
+		r.crashes = 1
+	}
+	if r.crashes > serverMaxCrashes {
+		r.printf("[watch] %s crashed %d times in a row, waiting for changes\n", r.label, serverMaxCrashes)
+		r.crashes = 0
+		return
+	}
+
+	delay := r.restartDelay << (r.crashes - 1)
+	if delay > serverMaxRestartDelay {
+		delay = serverMaxRestartDelay
+	}
+	r.printf("[watch] restarting %s in %s\n", r.label, delay)
+	r.retry = time.AfterFunc(delay, r.restart)
+}
+
+func (r *watchRunner) stopRetry() {
+	if r.retry != nil {
+		r.retry.Stop()
+		r.retry = nil
+	}
+}
+
+// stop cancels the current run, drops pending ones and waits until the
+// runner is idle.
+func (r *watchRunner) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pending = false
+	r.stopRetry()
+	if r.active {
+		r.cancel()
+	}
+	for r.active {
+		r.idle.Wait()
+	}
+}
+
+// wait blocks until no run is in progress or pending.
+func (r *watchRunner) wait() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.active {
+		r.idle.Wait()
+	}
+}
+
+func (r *watchRunner) printf(format string, args ...interface{}) {
+	if r.out != nil {
+		_, _ = fmt.Fprintf(r.out, format, args...)
+	}
+}
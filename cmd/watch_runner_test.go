@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingRun returns a run function that blocks until it is cancelled or
+// released, recording how each run ended.
+type blockingRun struct {
+	mu       sync.Mutex
+	started  chan int
+	release  chan struct{}
+	runs     int
+	outcomes []string
+}
+
+func newBlockingRun() *blockingRun {
+	return &blockingRun{started: make(chan int, 10), release: make(chan struct{}, 10)}
+}
+
+func (b *blockingRun) run(ctx context.Context, ready func()) error {
+	b.mu.Lock()
+	b.runs++
+	n := b.runs
+	b.mu.Unlock()
+	b.started <- n
+
+	select {
+	case <-ctx.Done():
+		b.record("cancelled")
+		return ctx.Err()
+	case <-b.release:
+		b.record("finished")
+		return nil
+	}
+}
+
+func (b *blockingRun) record(outcome string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.outcomes = append(b.outcomes, outcome)
+}
+
+func waitStarted(t *testing.T, b *blockingRun, expected int) {
+	t.Helper()
+	select {
+	case n := <-b.started:
+		if n != expected {
+			t.Fatalf("expected run %d to start, got run %d", expected, n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("run %d did not start", expected)
+	}
+}
+
+func TestWatchRunner_CancelsStaleRun(t *testing.T) {
+	b := newBlockingRun()
+	var out bytes.Buffer
+	runner := newWatchRunner("build", false, &syncWriter{w: &out}, b.run)
+
+	runner.trigger()
+	waitStarted(t, b, 1)
+
+	// A change during run 1 cancels it and starts run 2
+	runner.trigger()
+	waitStarted(t, b, 2)
+
+	b.release <- struct{}{}
+	runner.wait()
+
+	if strings.Join(b.outcomes, ",") != "cancelled,finished" {
+		t.Errorf("unexpected outcomes: %v", b.outcomes)
+	}
+	output := out.String()
+	for _, expected := range []string{
+		"[watch] run 1 started (task: build)",
+		"[watch] run 1 cancelled after",
+		"[watch] run 2 started (task: build)",
+		"[watch] run 2 finished in",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+}
+
+func TestWatchRunner_QueuesRuns(t *testing.T) {
+	b := newBlockingRun()
+	runner := newWatchRunner("build", true, nil, b.run)
+
+	runner.trigger()
+	waitStarted(t, b, 1)
+
+	// Several changes during run 1 collapse into a single follow-up run
+	runner.trigger()
+	runner.trigger()
+
+	b.release <- struct{}{}
+	waitStarted(t, b, 2)
+	b.release <- struct{}{}
+	runner.wait()
+
+	if strings.Join(b.outcomes, ",") != "finished,finished" {
+		t.Errorf("unexpected outcomes: %v", b.outcomes)
+	}
+	if b.runs != 2 {
+		t.Errorf("expected 2 runs, got %d", b.runs)
+	}
+}
+
+func TestWatchRunner_Stop(t *testing.T) {
+	b := newBlockingRun()
+	runner := newWatchRunner("build", true, nil, b.run)
+
+	runner.trigger()
+	waitStarted(t, b, 1)
+	runner.trigger()
+	runner.stop()
+
+	if strings.Join(b.outcomes, ",") != "cancelled" {
+		t.Errorf("expected only the current run to be cancelled, got %v", b.outcomes)
+	}
+}
+
+type syncWriter struct {
+	mu sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (s *syncWriter) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.String()
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+func TestWatchRunner_SharedSlot(t *testing.T) {
+	slot := make(chan struct{}, 1)
+	first, second := newBlockingRun(), newBlockingRun()
+	firstRunner := newWatchRunner("lint", false, nil, first.run)
+	secondRunner := newWatchRunner("build", false, nil, second.run)
+	firstRunner.slot = slot
+	secondRunner.slot = slot
+
+	firstRunner.trigger()
+	waitStarted(t, first, 1)
+	secondRunner.trigger()
+
+	select {
+	case <-second.started:
+		t.Fatal("expected the second task to wait for the first one")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	first.release <- struct{}{}
+	waitStarted(t, second, 1)
+	second.release <- struct{}{}
+	firstRunner.wait()
+	secondRunner.wait()
+}
+
+func TestWatchRunner_ServerSkipsSlot(t *testing.T) {
+	slot := make(chan struct{}, 1)
+	server, build := newBlockingRun(), newBlockingRun()
+	serverRunner := newWatchRunner("server", false, nil, server.run)
+	buildRunner := newWatchRunner("build", false, nil, build.run)
+	serverRunner.server = true
+	serverRunner.slot = slot
+	buildRunner.slot = slot
+
+	serverRunner.trigger()
+	waitStarted(t, server, 1)
+	buildRunner.trigger()
+	waitStarted(t, build, 1)
+
+	build.release <- struct{}{}
+	buildRunner.wait()
+	serverRunner.stop()
+}
+
+func TestWatchRunner_ServerCrashBackoff(t *testing.T) {
+	var mu sync.Mutex
+	runs := 0
+	var out bytes.Buffer
+	writer := &syncWriter{w: &out}
+
+	runner := newWatchRunner("server", false, writer, func(ctx context.Context, ready func()) error {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		return errors.New("exit status 1")
+	})
+	runner.server = true
+	runner.restartDelay = time.Millisecond
+
+	runner.trigger()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(writer.String(), "waiting for changes") {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the runner to give up, got:\n%s", writer.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	runner.wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != serverMaxCrashes+1 {
+		t.Errorf("expected %d runs, got %d", serverMaxCrashes+1, runs)
+	}
+	for _, expected := range []string{"restarting server in 1ms", "restarting server in 2ms", "restarting server in 16ms"} {
+		if !strings.Contains(writer.String(), expected) {
+			t.Errorf("expected output to contain %q, got:\n%s", expected, writer.String())
+		}
+	}
+}
+
+func TestWatchRunner_ServerChangeRestartsImmediately(t *testing.T) {
+	b := newBlockingRun()
+	var out bytes.Buffer
+	writer := &syncWriter{w: &out}
+	runner := newWatchRunner("server", false, writer, func(ctx context.Context, ready func()) error {
+		ready()
+		return b.run(ctx, ready)
+	})
+	runner.server = true
+
+	runner.trigger()
+	waitStarted(t, b, 1)
+	runner.trigger()
+	waitStarted(t, b, 2)
+	runner.stop()
+
+	output := writer.String()
+	if !strings.Contains(output, "[watch] run 1 ready after") {
+		t.Errorf("expected readiness to be reported, got:\n%s", output)
+	}
+	if strings.Contains(output, "restarting") {
+		t.Errorf("expected a cancelled server not to be treated as crashed, got:\n%s", output)
+	}
+}
+
+func TestWatchRunner_ServerIgnoresQueue(t *testing.T) {
+	b := newBlockingRun()
+	runner := newWatchRunner("server", true, nil, b.run)
+	runner.server = true
+
+	runner.trigger()
+	waitStarted(t, b, 1)
+	// Queued behind a server, the change would wait forever
+	runner.trigger()
+	waitStarted(t, b, 2)
+	runner.stop()
+
+	if strings.Join(b.outcomes, ",") != "cancelled,cancelled" {
+		t.Errorf("unexpected outcomes: %v", b.outcomes)
+	}
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+	"github.com/spf13/cobra"
+)
+
+// watchTarget is a task run by watch, together with the changes it reacts to.
+// Every target is debounced on its own.
+type watchTarget struct {
+	label  string
+	filter *watchFilter
+	// patterns are the --on patterns routed to the task
+	patterns []string
+	delay    time.Duration
+	queue    bool
+	poll     bool
+	// server is set for long-running tasks that are restarted after crashes
+	server bool
+	runner *watchRunner
+	timer  *time.Timer
+}
+
+// debounce (re)starts the target's delay before the next run.
+func (t *watchTarget) debounce() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	t.timer = time.AfterFunc(t.delay, t.runner.trigger)
+}
+
+// stop cancels a pending and the current run.
+func (t *watchTarget) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	if t.runner != nil {
+		t.runner.stop()
+	}
+}
+
+// buildWatchTargets creates a target for every task named on the command line
+// or in a --on route, where a glob names every matching task. Routed tasks
+// only react to the routed patterns.
+func buildWatchTargets(cmd *cobra.Command, tasks []config.Task, args []string, workspaceDir string) ([]*watchTarget, error) {
+	var selected []*config.Task
+	patterns := make(map[string][]string)
+	add := func(name string, pattern string) error {
+		matches, err := lookupTasks(tasks, name)
+		if err != nil {
+			return err
+		}
+		for _, task := range matches {
+			if _, ok := patterns[task.Label]; !ok {
+				selected = append(selected, task)
+				patterns[task.Label] = nil
+			}
+			if pattern != "" {
+				patterns[task.Label] = append(patterns[task.Label], pattern)
+			}
+		}
+		return nil
+	}
+
+	for _, name := range args {
+		if err := add(name, ""); err != nil {
+			return nil, err
+		}
+	}
+	for _, route := range watchOn {
+		pattern, name, ok := strings.Cut(route, "=")
+		if !ok || pattern == "" || name == "" {
+			return nil, fmt.Errorf("invalid --on route '%s', expected <pattern>=<task>", route)
+		}
+		if err := add(name, pattern); err != nil {
+			return nil, err
+		}
+	}
+
+	var targets []*watchTarget
+	for _, task := range selected {
+		target, err := newWatchTarget(cmd, task, workspaceDir, patterns[task.Label])
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// watchRouteTasks returns the task names of the --on routes.
+func watchRouteTasks() []string {
+	var names []string
+	for _, route := range watchOn {
+		if _, name, ok := strings.Cut(route, "="); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newWatchTarget combines the command line flags with the task's "x-watch"
+// block, which supplies the value of every flag not given explicitly. Excludes
+// are added to the default exclusions.
+func newWatchTarget(cmd *cobra.Command, task *config.Task, workspaceDir string, patterns []string) (*watchTarget, error) {
+	flags := cmd.Flags()
+	target := &watchTarget{
+		label:    task.Label,
+		filter:   newWatchFilter(workspaceDir),
+		patterns: patterns,
+		delay:    watchDelay,
+		queue:    watchQueue,
+		server:   watchRestart || executor.IsBackgroundTask(task),
+	}
+	if len(patterns) > 0 {
+		target.filter.includes = patterns
+	}
+
+	watch := task.Watch
+	if watch == nil {
+		return target, nil
+	}
+
+	if len(watch.Paths) > 0 && !flags.Changed("path") {
+		for _, path := range watch.Paths {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(workspaceDir, path)
+			}
+			target.filter.paths = append(target.filter.paths, path)
+		}
+	}
+	if len(watch.Include) > 0 && len(patterns) == 0 && !flags.Changed("include") {
+		target.filter.includes = watch.Include
+	}
+	if len(watch.Exclude) > 0 && !flags.Changed("exclude") {
+		target.filter.excludes = append(append([]string{}, target.filter.excludes...), watch.Exclude...)
+	}
+	if watch.Delay != nil && !flags.Changed("delay") {
+		delay, err := watch.GetDelay()
+		if err != nil {
+			return nil, fmt.Errorf("invalid x-watch delay of task '%s': %w", task.Label, err)
+		}
+		target.delay = delay
+	}
+	if watch.Restart != nil && !flags.Changed("queue") {
+		target.queue = !*watch.Restart
+	}
+	if watch.Server != nil && !flags.Changed("restart") {
+		target.server = *watch.Server || executor.IsBackgroundTask(task)
+	}
+	if watch.Poll != nil && !flags.Changed("poll") {
+		target.poll = *watch.Poll
+	}
+	return target, nil
+}
+
+// defaultWatchPaths returns the x-watch paths of the targets, or the whole
+// workspace if any target is not restricted to paths.
+func defaultWatchPaths(targets []*watchTarget, workspaceDir string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if len(target.filter.paths) == 0 {
+			return []string{workspaceDir}
+		}
+		for _, path := range target.filter.paths {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// reloadWatchTargets applies reloaded task definitions to the targets. If a
+// task is gone or its x-watch block is invalid, no target is changed.
+func reloadWatchTargets(cmd *cobra.Command, tasks []config.Task, targets []*watchTarget, workspaceDir string) error {
+	reloaded := make([]*watchTarget, len(targets))
+	for i, target := range targets {
+		task, err := lookupTask(tasks, target.label)
+		if err != nil {
+			return err
+		}
+		if reloaded[i], err = newWatchTarget(cmd, task, workspaceDir, target.patterns); err != nil {
+			return err
+		}
+	}
+
+	for i, target := range targets {
+		target.filter = reloaded[i].filter
+		target.delay = reloaded[i].delay
+		target.queue = reloaded[i].queue
+		target.poll = reloaded[i].poll
+		target.server = reloaded[i].server
+		if target.runner != nil {
+			target.runner.configure(target.queue, target.server)
+		}
+	}
+	return nil
+}
+
+// newDirFilter returns the filter deciding which directories are registered
+// with the watcher. A directory is left out only if every target excludes it.
+func newDirFilter(targets []*watchTarget, workspaceDir string) *watchFilter {
+	filter := newWatchFilter(workspaceDir)
+	if len(targets) > 0 {
+		filter.excludes = commonExcludes(targets)
+	}
+	return filter
+}
+
+// commonExcludes returns the exclude patterns shared by every target.
+func commonExcludes(targets []*watchTarget) []string {
+	var common []string
+	for _, pattern := range targets[0].filter.excludes {
+		shared := true
+		for _, target := range targets[1:] {
+			if !slices.Contains(target.filter.excludes, pattern) {
+				shared = false
+				break
+			}
+		}
+		if shared {
+			common = append(common, pattern)
+		}
+	}
+	return common
+}
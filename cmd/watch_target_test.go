@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newWatchTestCommand() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().StringSliceVar(&watchInclude, "include", []string{}, "")
+	cmd.Flags().StringSliceVar(&watchExclude, "exclude", []string{"node_modules"}, "")
+	cmd.Flags().DurationVar(&watchDelay, "delay", 500*time.Millisecond, "")
+	cmd.Flags().BoolVar(&watchQueue, "queue", false, "")
+	cmd.Flags().BoolVar(&watchRestart, "restart", false, "")
+	return cmd
+}
+
+func saveWatchFlags(t *testing.T) {
+	origPaths, origInclude, origExclude, origOn := watchPaths, watchInclude, watchExclude, watchOn
+	origDelay, origQueue, origPoll, origRestart := watchDelay, watchQueue, watchPoll, watchRestart
+	t.Cleanup(func() {
+		watchPaths, watchInclude, watchExclude, watchOn = origPaths, origInclude, origExclude, origOn
+		watchDelay, watchQueue, watchPoll, watchRestart = origDelay, origQueue, origPoll, origRestart
+	})
+}
+
+func TestNewWatchTarget_WatchConfig(t *testing.T) {
+	saveWatchFlags(t)
+	root := t.TempDir()
+
+	restart := false
+	server := true
+	poll := true
+	task := &config.Task{
+		Label: "build",
+		Watch: &config.WatchConfig{
+			Paths:   []string{"src"},
+			Include: []string{"**/*.go"},
+			Exclude: []string{"gen"},
+			Delay:   "300ms",
+			Restart: &restart,
+			Server:  &server,
+			Poll:    &poll,
+		},
+	}
+
+	target, err := newWatchTarget(newWatchTestCommand(), task, root, nil)
+	if err != nil {
+		t.Fatalf("newWatchTarget failed: %v", err)
+	}
+	if strings.Join(target.filter.includes, ",") != "**/*.go" {
+		t.Errorf("expected includes from x-watch, got %v", target.filter.includes)
+	}
+	if strings.Join(target.filter.excludes, ",") != "node_modules,gen" {
+		t.Errorf("expected x-watch excludes added to the defaults, got %v", target.filter.excludes)
+	}
+	if len(target.filter.paths) != 1 || target.filter.paths[0] != filepath.Join(root, "src") {
+		t.Errorf("expected paths resolved against the workspace, got %v", target.filter.paths)
+	}
+	if target.delay != 300*time.Millisecond || !target.queue || !target.poll || !target.server {
+		t.Errorf("expected delay 300ms, queue, poll and server mode, got %v %v %v %v", target.delay, target.queue, target.poll, target.server)
+	}
+	if paths := defaultWatchPaths([]*watchTarget{target}, root); len(paths) != 1 || paths[0] != filepath.Join(root, "src") {
+		t.Errorf("expected x-watch paths to be watched, got %v", paths)
+	}
+
+	event := fsnotify.Event{Name: filepath.Join(root, "docs", "main.go"), Op: fsnotify.Write}
+	if shouldHandleEvent(target.filter, event) {
+		t.Error("expected files outside the x-watch paths to be ignored")
+	}
+
+	// Flags given on the command line win
+	cmd := newWatchTestCommand()
+	if err := cmd.Flags().Parse([]string{"--include", "*.ts", "--delay", "1s"}); err != nil {
+		t.Fatal(err)
+	}
+	target, err = newWatchTarget(cmd, task, root, nil)
+	if err != nil {
+		t.Fatalf("newWatchTarget failed: %v", err)
+	}
+	if strings.Join(target.filter.includes, ",") != "*.ts" || target.delay != time.Second {
+		t.Errorf("expected command line flags to override x-watch, got %v %v", target.filter.includes, target.delay)
+	}
+
+	// "restart" reruns the task on changes, it does not make it a server
+	restart = true
+	task.Watch = &config.WatchConfig{Restart: &restart}
+	target, err = newWatchTarget(newWatchTestCommand(), task, root, nil)
+	if err != nil {
+		t.Fatalf("newWatchTarget failed: %v", err)
+	}
+	if target.queue || target.server {
+		t.Errorf("expected restart to cancel runs without server mode, got queue %v, server %v", target.queue, target.server)
+	}
+
+	task.Watch = &config.WatchConfig{Delay: "soon"}
+	if _, err := newWatchTarget(newWatchTestCommand(), task, root, nil); err == nil {
+		t.Error("expected an error for an invalid delay")
+	}
+}
+
+func TestBuildWatchTargets_Routes(t *testing.T) {
+	saveWatchFlags(t)
+	root := t.TempDir()
+
+	tasks := []config.Task{
+		{Label: "lint-go", Type: "shell", Command: "golangci-lint run"},
+		{Label: "build-css", Type: "shell", Command: "sass"},
+		{Label: "gen", Type: "shell", Command: "buf generate", Watch: &config.WatchConfig{Include: []string{"proto/**"}}},
+		{Label: "docs", Type: "shell", Command: "mkdocs build"},
+	}
+
+	watchOn = []string{"*.go=lint-go", "*.scss=build-css", "*.sass=build-css"}
+	targets, err := buildWatchTargets(newWatchTestCommand(), tasks, []string{"gen", "docs"}, root)
+	if err != nil {
+		t.Fatalf("buildWatchTargets failed: %v", err)
+	}
+
+	var labels []string
+	for _, target := range targets {
+		labels = append(labels, target.label)
+	}
+	if strings.Join(labels, ",") != "gen,docs,lint-go,build-css" {
+		t.Fatalf("unexpected targets: %v", labels)
+	}
+
+	route := func(path string) []string {
+		var matched []string
+		event := fsnotify.Event{Name: filepath.Join(root, path), Op: fsnotify.Write}
+		for _, target := range targets {
+			if shouldHandleEvent(target.filter, event) {
+				matched = append(matched, target.label)
+			}
+		}
+		return matched
+	}
+
+	tests := map[string]string{
+		"cmd/main.go":     "docs,lint-go",
+		"web/app.scss":    "docs,build-css",
+		"web/theme.sass":  "docs,build-css",
+		"proto/api.proto": "gen,docs",
+		"README.md":       "docs",
+	}
+	for path, expected := range tests {
+		if got := strings.Join(route(path), ","); got != expected {
+			t.Errorf("change to %s triggers %q, want %q", path, got, expected)
+		}
+	}
+
+	watchOn = []string{"*.go"}
+	if _, err := buildWatchTargets(newWatchTestCommand(), tasks, nil, root); err == nil {
+		t.Error("expected an error for a route without a task")
+	}
+
+	watchOn = []string{"*.go=missing"}
+	if _, err := buildWatchTargets(newWatchTestCommand(), tasks, nil, root); err == nil || err.Error() != "task 'missing' not found" {
+		t.Errorf("expected task not found error, got %v", err)
+	}
+}
+
+func TestReloadWatchTargets(t *testing.T) {
+	saveWatchFlags(t)
+	root := t.TempDir()
+	watchOn = []string{"*.go=build"}
+	tasks := []config.Task{{Label: "build"}, {Label: "test"}}
+	targets, err := buildWatchTargets(newWatchTestCommand(), tasks, []string{"test"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restart := false
+	tasks[0].Watch = &config.WatchConfig{Exclude: []string{"gen"}, Delay: 100.0, Restart: &restart}
+	if err := reloadWatchTargets(newWatchTestCommand(), tasks, targets, root); err != nil {
+		t.Fatalf("reloadWatchTargets failed: %v", err)
+	}
+	build := targets[1]
+	if strings.Join(build.filter.includes, ",") != "*.go" || strings.Join(build.filter.excludes, ",") != "node_modules,gen" {
+		t.Errorf("expected the routed patterns and the new excludes, got %v and %v", build.filter.includes, build.filter.excludes)
+	}
+	if build.delay != 100*time.Millisecond || !build.queue {
+		t.Errorf("expected the new delay and queue mode, got %v %v", build.delay, build.queue)
+	}
+
+	tasks[0].Watch = &config.WatchConfig{Delay: "soon"}
+	if err := reloadWatchTargets(newWatchTestCommand(), tasks, targets, root); err == nil {
+		t.Error("expected an error for an invalid delay")
+	}
+	if err := reloadWatchTargets(newWatchTestCommand(), tasks[1:], targets, root); err == nil || err.Error() != "task 'build' not found" {
+		t.Errorf("expected missing task error, got %v", err)
+	}
+	if build.delay != 100*time.Millisecond {
+		t.Errorf("expected failed reloads to keep the targets, got delay %v", build.delay)
+	}
+}
+
+func TestNewDirFilter(t *testing.T) {
+	root := t.TempDir()
+	targets := []*watchTarget{
+		{filter: &watchFilter{excludes: []string{"node_modules", "gen", "docs"}}},
+		{filter: &watchFilter{excludes: []string{"node_modules", "docs"}}},
+	}
+	filter := newDirFilter(targets, root)
+	if strings.Join(filter.excludes, ",") != "node_modules,docs" {
+		t.Errorf("expected the excludes shared by every target, got %v", filter.excludes)
+	}
+	if filter.skipDir(filepath.Join(root, "gen")) || !filter.skipDir(filepath.Join(root, "docs")) {
+		t.Error("expected only directories every target excludes to be skipped")
+	}
+}
+
+func TestExecuteWatchCommand_NoTask(t *testing.T) {
+	saveWatchFlags(t)
+	watchOn = nil
+
+	err := executeWatchCommand(&cobra.Command{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "--on") {
+		t.Errorf("expected an error asking for a task or route, got %v", err)
+	}
+}
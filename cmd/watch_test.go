@@ -70,7 +70,7 @@ func TestShouldHandleEvent(t *testing.T) {
 			watchExclude = tt.excludes
 
 			// Test
-			result := shouldHandleEvent(tt.event)
+			result := shouldHandleEvent(newWatchFilter(""), tt.event)
 
 			// Restore original values
 			watchExtensions = origExtensions
@@ -121,7 +121,7 @@ func TestAddWatchPath(t *testing.T) {
 	defer func() { watchExclude = origExcludes }()
 
 	// Test adding watch path
-	err = addWatchPath(watcher, tempDir)
+	err = addWatchPath(watcher, newWatchFilter(tempDir), tempDir)
 	if err != nil {
 		t.Errorf("addWatchPath() error = %v", err)
 	}
@@ -137,7 +137,7 @@ func TestAddWatchPath_NonExistentPath(t *testing.T) {
 	}
 	defer func() { _ = watcher.Close() }()
 
-	err = addWatchPath(watcher, "/nonexistent/path")
+	err = addWatchPath(watcher, newWatchFilter("/nonexistent"), "/nonexistent/path")
 	if err == nil {
 		t.Error("expected error for non-existent path")
 	}
@@ -436,4 +436,159 @@ func TestExecuteWatchCommand_DefaultWatchPaths(t *testing.T) {
 	if !strings.Contains(err.Error(), "failed to watch path") {
 		t.Errorf("expected watch path error, got %s", err.Error())
 	}
-}
\ No newline at end of file
+}
+
+func TestWatchFilter_GlobsAndIgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("dist/\n*.tmp\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origIncludes, origExcludes, origExtensions, origNoIgnore := watchInclude, watchExclude, watchExtensions, watchNoIgnore
+	defer func() {
+		watchInclude, watchExclude, watchExtensions, watchNoIgnore = origIncludes, origExcludes, origExtensions, origNoIgnore
+	}()
+
+	watchInclude = []string{"src/**"}
+	watchExclude = []string{"build", "**/*_test.go"}
+	watchExtensions = nil
+	watchNoIgnore = false
+	filter := newWatchFilter(root)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"src/buildinfo.go", true},
+		{"src/build/out.go", false},
+		{"src/main_test.go", false},
+		{"src/dist/app.js", false},
+		{"src/scratch.tmp", false},
+		{"docs/readme.md", false},
+	}
+	for _, tt := range tests {
+		event := fsnotify.Event{Name: filepath.Join(root, tt.path), Op: fsnotify.Write}
+		if got := shouldHandleEvent(filter, event); got != tt.expected {
+			t.Errorf("shouldHandleEvent(%s) = %v, want %v", tt.path, got, tt.expected)
+		}
+	}
+
+	watchNoIgnore = true
+	filter = newWatchFilter(root)
+	event := fsnotify.Event{Name: filepath.Join(root, "src/dist/app.js"), Op: fsnotify.Write}
+	if !shouldHandleEvent(filter, event) {
+		t.Error("expected --no-ignore to watch gitignored files")
+	}
+}
+
+func TestAddWatchPath_SkipsIgnoredDirectories(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"src/pkg", "dist/assets", "target/debug", "node_modules/lib"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("/dist\ntarget/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	origExcludes, origNoIgnore := watchExclude, watchNoIgnore
+	watchExclude = []string{"node_modules"}
+	watchNoIgnore = false
+	defer func() { watchExclude, watchNoIgnore = origExcludes, origNoIgnore }()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = watcher.Close() }()
+
+	if err := addWatchPath(watcher, newWatchFilter(root), root); err != nil {
+		t.Fatalf("addWatchPath() error = %v", err)
+	}
+
+	watched := make(map[string]bool)
+	for _, path := range watcher.WatchList() {
+		rel, _ := filepath.Rel(root, path)
+		watched[filepath.ToSlash(rel)] = true
+	}
+	for _, dir := range []string{".", "src", "src/pkg"} {
+		if !watched[dir] {
+			t.Errorf("expected %s to be watched", dir)
+		}
+	}
+	for _, dir := range []string{"dist", "dist/assets", "target", "target/debug", "node_modules"} {
+		if watched[dir] {
+			t.Errorf("expected %s not to be watched", dir)
+		}
+	}
+}
+
+func TestShouldHandleEvent_Removals(t *testing.T) {
+	origRemovals := watchRemovals
+	defer func() { watchRemovals = origRemovals }()
+
+	for _, op := range []fsnotify.Op{fsnotify.Remove, fsnotify.Rename} {
+		watchRemovals = false
+		if shouldHandleEvent(newWatchFilter(""), fsnotify.Event{Name: "main.go", Op: op}) {
+			t.Errorf("expected %s event to be ignored by default", op)
+		}
+		watchRemovals = true
+		if !shouldHandleEvent(newWatchFilter(""), fsnotify.Event{Name: "main.go", Op: op}) {
+			t.Errorf("expected %s event to be handled with --removals", op)
+		}
+	}
+}
+
+func TestIsConfigFileEvent(t *testing.T) {
+	dir := t.TempDir()
+	tasksFile := filepath.Join(dir, ".vscode", "tasks.json")
+	configFiles := watchedConfigFiles(tasksFile)
+
+	tests := []struct {
+		event    fsnotify.Event
+		expected bool
+	}{
+		{fsnotify.Event{Name: tasksFile, Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: tasksFile, Op: fsnotify.Create}, true},
+		{fsnotify.Event{Name: filepath.Join(dir, ".vscode", "settings.json"), Op: fsnotify.Write}, true},
+		{fsnotify.Event{Name: tasksFile, Op: fsnotify.Chmod}, false},
+		{fsnotify.Event{Name: filepath.Join(dir, ".vscode", "launch.json"), Op: fsnotify.Write}, false},
+	}
+	for _, tt := range tests {
+		if got := isConfigFileEvent(configFiles, tt.event); got != tt.expected {
+			t.Errorf("isConfigFileEvent(%v) = %v, want %v", tt.event, got, tt.expected)
+		}
+	}
+}
+
+func TestNewDirectoryHandling(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg", "api"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "api", "handler.go"), []byte("package api"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	origExtensions := watchExtensions
+	watchExtensions = []string{".go"}
+	defer func() { watchExtensions = origExtensions }()
+	filter := newWatchFilter(root)
+
+	if !isNewDirectory(fsnotify.Event{Name: filepath.Join(root, "pkg"), Op: fsnotify.Create}) {
+		t.Error("expected created directory to be detected")
+	}
+	if isNewDirectory(fsnotify.Event{Name: filepath.Join(root, "pkg", "api", "handler.go"), Op: fsnotify.Create}) {
+		t.Error("expected created file not to be treated as a directory")
+	}
+	if !containsWatchedFile(filter, filepath.Join(root, "pkg")) {
+		t.Error("expected new directory with a .go file to trigger the task")
+	}
+	if containsWatchedFile(filter, filepath.Join(root, "assets")) {
+		t.Error("expected empty directory not to trigger the task")
+	}
+}
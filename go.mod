@@ -3,13 +3,13 @@ module github.com/garaemon/tasks-json-cli
 go 1.24.4
 
 require (
+	github.com/bmatcuk/doublestar/v4 v4.8.1
 	github.com/fsnotify/fsnotify v1.9.0
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	github.com/tidwall/jsonc v0.3.2
+	golang.org/x/sys v0.13.0
+	golang.org/x/term v0.10.0
 )
 
-require (
-	github.com/inconshreveable/mousetrap v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.6 // indirect
-	golang.org/x/sys v0.13.0 // indirect
-)
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
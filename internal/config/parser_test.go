@@ -1,8 +1,11 @@
 package config
 
 import (
+	"encoding/json"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadTasks_SimpleFile(t *testing.T) {
@@ -244,4 +247,102 @@ func TestTask_IsDefaultInGroup(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestLoadTasks_KeepsDefinition(t *testing.T) {
+	tempFile := filepath.Join(t.TempDir(), "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{"label": "app", "type": "bazel", "target": "//app:all"}
+		]
+	}`
+	if err := os.WriteFile(tempFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks(tempFile)
+	if err != nil {
+		t.Fatalf("LoadTasks failed: %v", err)
+	}
+
+	if tasks[0].Label != "app" {
+		t.Errorf("Expected label 'app', got '%s'", tasks[0].Label)
+	}
+	if tasks[0].Definition["target"] != "//app:all" {
+		t.Errorf("Expected custom property in definition, got %v", tasks[0].Definition)
+	}
+}
+
+func TestLoadTasks_WatchConfig(t *testing.T) {
+	tasksFile := filepath.Join(t.TempDir(), "tasks.json")
+	content := `{
+		"version": "2.0.0",
+		"tasks": [
+			{
+				"label": "build",
+				"type": "shell",
+				"command": "go build ./...",
+				"x-watch": {"include": ["**/*.go"], "exclude": ["gen/**"], "delay": "300ms", "restart": true, "server": false}
+			}
+		]
+	}`
+	if err := os.WriteFile(tasksFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := LoadTasks(tasksFile)
+	if err != nil {
+		t.Fatalf("LoadTasks failed: %v", err)
+	}
+
+	watch := tasks[0].Watch
+	if watch == nil {
+		t.Fatal("expected x-watch to be parsed")
+	}
+	if len(watch.Include) != 1 || watch.Include[0] != "**/*.go" || len(watch.Exclude) != 1 {
+		t.Errorf("unexpected patterns: %+v", watch)
+	}
+	if delay, err := watch.GetDelay(); err != nil || delay != 300*time.Millisecond {
+		t.Errorf("expected delay of 300ms, got %v (%v)", delay, err)
+	}
+	if watch.Restart == nil || !*watch.Restart || watch.Server == nil || *watch.Server {
+		t.Error("expected restart to be true and server false")
+	}
+}
+
+func TestWatchConfig_GetDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		delay       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{"unset", `{}`, 0, false},
+		{"duration string", `{"delay": "1.5s"}`, 1500 * time.Millisecond, false},
+		{"milliseconds", `{"delay": 300}`, 300 * time.Millisecond, false},
+		{"invalid string", `{"delay": "soon"}`, 0, true},
+		{"negative", `{"delay": -1}`, 0, true},
+		{"negative string", `{"delay": "-1s"}`, 0, true},
+		{"wrong type", `{"delay": true}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var watch WatchConfig
+			if err := json.Unmarshal([]byte(tt.delay), &watch); err != nil {
+				t.Fatal(err)
+			}
+			delay, err := watch.GetDelay()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got %v", delay)
+				}
+				return
+			}
+			if err != nil || delay != tt.expected {
+				t.Errorf("expected %v, got %v (%v)", tt.expected, delay, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tidwall/jsonc"
+)
+
+// Settings holds the workspace settings read from VS Code's settings.json.
+// Keys are kept in their flat, dotted form (e.g. "npm.exclude").
+type Settings struct {
+	values map[string]interface{}
+}
+
+// LoadSettings reads a settings.json file. A missing file yields empty settings.
+func LoadSettings(filePath string) (*Settings, error) {
+	settings := &Settings{values: map[string]interface{}{}}
+	if filePath == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return nil, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := json.Unmarshal(jsonc.ToJSON(data), &settings.values); err != nil {
+		return nil, fmt.Errorf("failed to parse settings file: %w", err)
+	}
+
+	return settings, nil
+}
+
+// Set overrides a single setting value.
+func (s *Settings) Set(key string, value interface{}) {
+	if s.values == nil {
+		s.values = map[string]interface{}{}
+	}
+	s.values[key] = value
+}
+
+func (s *Settings) lookup(key string) (interface{}, bool) {
+	if s == nil || s.values == nil {
+		return nil, false
+	}
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// GetString returns the string value of key, or defaultValue if unset.
+func (s *Settings) GetString(key string, defaultValue string) string {
+	if value, ok := s.lookup(key); ok {
+		if str, ok := value.(string); ok {
+			return str
+		}
+	}
+	return defaultValue
+}
+
+// GetStringSlice returns key as a list, accepting either a single string or an array.
+func (s *Settings) GetStringSlice(key string) []string {
+	value, ok := s.lookup(key)
+	if !ok {
+		return nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				result = append(result, str)
+			}
+		}
+		return result
+	case []string:
+		return v
+	}
+
+	return nil
+}
+
+// IsAutoDetectEnabled reports whether an "<type>.autoDetect" style setting is on.
+// VS Code uses "on"/"off" for these, booleans are accepted as well. Unset means on.
+func (s *Settings) IsAutoDetectEnabled(key string) bool {
+	value, ok := s.lookup(key)
+	if !ok {
+		return true
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v != "off"
+	case bool:
+		return v
+	}
+
+	return true
+}
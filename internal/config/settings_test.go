@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSettings(t *testing.T) {
+	tempDir := t.TempDir()
+	settingsFile := filepath.Join(tempDir, "settings.json")
+	content := `{
+		// JSONC comments are allowed in settings.json
+		"npm.exclude": ["**/vendor/**", "examples"],
+		"npm.packageManager": "pnpm",
+		"npm.autoDetect": "off",
+	}`
+	if err := os.WriteFile(settingsFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := LoadSettings(settingsFile)
+	if err != nil {
+		t.Fatalf("LoadSettings failed: %v", err)
+	}
+
+	excludes := settings.GetStringSlice("npm.exclude")
+	if len(excludes) != 2 || excludes[0] != "**/vendor/**" || excludes[1] != "examples" {
+		t.Errorf("unexpected npm.exclude: %v", excludes)
+	}
+	if pm := settings.GetString("npm.packageManager", "auto"); pm != "pnpm" {
+		t.Errorf("expected packageManager 'pnpm', got '%s'", pm)
+	}
+	if settings.IsAutoDetectEnabled("npm.autoDetect") {
+		t.Error("expected npm.autoDetect to be disabled")
+	}
+	if !settings.IsAutoDetectEnabled("gulp.autoDetect") {
+		t.Error("expected unset autoDetect setting to default to enabled")
+	}
+}
+
+func TestLoadSettings_MissingFile(t *testing.T) {
+	settings, err := LoadSettings(filepath.Join(t.TempDir(), "settings.json"))
+	if err != nil {
+		t.Fatalf("expected no error for missing settings file, got %v", err)
+	}
+	if got := settings.GetString("npm.packageManager", "auto"); got != "auto" {
+		t.Errorf("expected default value, got '%s'", got)
+	}
+}
+
+func TestLoadSettings_InvalidJSON(t *testing.T) {
+	settingsFile := filepath.Join(t.TempDir(), "settings.json")
+	if err := os.WriteFile(settingsFile, []byte(`{"npm.exclude": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := LoadSettings(settingsFile); err == nil {
+		t.Error("expected error for invalid settings file")
+	}
+}
+
+func TestSettings_GetStringSlice_SingleString(t *testing.T) {
+	settings := &Settings{}
+	settings.Set("npm.exclude", "**/dist")
+
+	excludes := settings.GetStringSlice("npm.exclude")
+	if len(excludes) != 1 || excludes[0] != "**/dist" {
+		t.Errorf("expected [**/dist], got %v", excludes)
+	}
+}
@@ -1,5 +1,11 @@
 package config
 
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type TasksFile struct {
 	Version string `json:"version"`
 	Tasks   []Task `json:"tasks"`
@@ -9,12 +15,14 @@ type Task struct {
 	Label           string            `json:"label"`
 	Type            string            `json:"type"`
 	Command         string            `json:"command"`
+	Detail          string            `json:"detail,omitempty"`
 	Args            []string          `json:"args,omitempty"`
 	Group           interface{}       `json:"group,omitempty"`
 	ProblemMatcher  interface{}       `json:"problemMatcher,omitempty"`
 	Options         *TaskOptions      `json:"options,omitempty"`
 	DependsOn       interface{}       `json:"dependsOn,omitempty"`
 	DependsOrder    string            `json:"dependsOrder,omitempty"`
+	IsBackground    bool              `json:"isBackground,omitempty"`
 	Presentation    *TaskPresentation `json:"presentation,omitempty"`
 	RunOptions      *TaskRunOptions   `json:"runOptions,omitempty"`
 	
@@ -25,6 +33,45 @@ type Task struct {
 	// NPM task specific fields
 	Script          string            `json:"script,omitempty"`
 	Path            string            `json:"path,omitempty"`
+	// PackageManager is the "npm.packageManager" setting the task runs with
+	PackageManager  string            `json:"-"`
+	
+	// Make task specific fields
+	Target          string            `json:"target,omitempty"`
+	
+	// Gulp and Grunt task specific fields
+	TaskName        string            `json:"task,omitempty"`
+	File            string            `json:"file,omitempty"`
+	
+	// Extension fields of tasks-json-cli, ignored by VS Code
+	Watch           *WatchConfig      `json:"x-watch,omitempty"`
+	// Inputs are globs of the files the task reads, relative to the workspace
+	Inputs          []string          `json:"x-inputs,omitempty"`
+	// Outputs are globs of the files the task writes, relative to the workspace
+	Outputs         []string          `json:"x-outputs,omitempty"`
+	// Cache lets the executor reuse results of earlier runs with the same inputs
+	Cache           bool              `json:"x-cache,omitempty"`
+	
+	// Definition holds the task object as written in tasks.json, including
+	// properties of custom task types that are not modeled above.
+	Definition      map[string]interface{} `json:"-"`
+}
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	type plainTask Task
+	var plain plainTask
+	if err := json.Unmarshal(data, &plain); err != nil {
+		return err
+	}
+
+	var definition map[string]interface{}
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return err
+	}
+
+	*t = Task(plain)
+	t.Definition = definition
+	return nil
 }
 
 type TaskOptions struct {
@@ -52,6 +99,51 @@ type TaskRunOptions struct {
 	RunOn string `json:"runOn,omitempty"`
 }
 
+// WatchConfig holds the "x-watch" defaults of the watch command for a task.
+type WatchConfig struct {
+	Paths   []string    `json:"paths,omitempty"`
+	Include []string    `json:"include,omitempty"`
+	Exclude []string    `json:"exclude,omitempty"`
+	// Delay is a number of milliseconds or a duration string such as "300ms"
+	Delay   interface{} `json:"delay,omitempty"`
+	// Restart cancels a run on a change and starts it again; false queues
+	// the change like --queue
+	Restart *bool       `json:"restart,omitempty"`
+	// Server treats the task as a long-running server, like --restart
+	Server  *bool       `json:"server,omitempty"`
+	Poll    *bool       `json:"poll,omitempty"`
+}
+
+// WatchConfigKeys are the properties understood in an "x-watch" block.
+var WatchConfigKeys = []string{"paths", "include", "exclude", "delay", "restart", "server", "poll"}
+
+// GetDelay parses the debounce delay, returning 0 if none is set.
+func (w *WatchConfig) GetDelay() (time.Duration, error) {
+	switch delay := w.Delay.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		if delay < 0 {
+			return 0, fmt.Errorf("negative delay %v", delay)
+		}
+		return time.Duration(delay * float64(time.Millisecond)), nil
+	case string:
+		if delay == "" {
+			return 0, nil
+		}
+		d, err := time.ParseDuration(delay)
+		if err != nil {
+			return 0, err
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("negative delay %s", delay)
+		}
+		return d, nil
+	default:
+		return 0, fmt.Errorf("delay must be a number of milliseconds or a duration string")
+	}
+}
+
 func (t *Task) GetGroupKind() string {
 	if t.Group == nil {
 		return ""
@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// cargoCommands are the cargo subcommands offered for every crate, like the
+// default tasks of rust-analyzer.
+var cargoCommands = []struct {
+	command string
+	group   string
+}{
+	{"build", "build"},
+	{"check", "build"},
+	{"test", "test"},
+	{"clippy", ""},
+	{"clean", ""},
+}
+
+// cargoProvider detects "cargo" tasks from Cargo.toml manifests.
+type cargoProvider struct{}
+
+func (p *cargoProvider) Type() string {
+	return "cargo"
+}
+
+func (p *cargoProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("cargo.autoDetect")
+}
+
+func (p *cargoProvider) FileNames() []string {
+	return []string{"Cargo.toml"}
+}
+
+func (p *cargoProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	workspaceDir := workspace.Dir
+	excludes := append([]string{"**/target"}, settings.GetStringSlice("cargo.exclude")...)
+	manifests := workspace.findFiles(p.FileNames(), excludes)
+
+	var tasks []config.Task
+	for _, manifest := range manifests {
+		crateDir := filepath.Dir(manifest)
+		path := relativeDir(workspaceDir, crateDir)
+
+		for _, c := range cargoCommands {
+			tasks = append(tasks, newCargoTask(c.command, path, c.group))
+		}
+		if fileExists(filepath.Join(crateDir, "src", "main.rs")) {
+			tasks = append(tasks, newCargoTask("run", path, ""))
+		}
+	}
+
+	return tasks, nil
+}
+
+func newCargoTask(command, path, group string) config.Task {
+	task := config.Task{
+		Label:   detectedTaskLabel("cargo", command, path),
+		Type:    "cargo",
+		Command: command,
+		Path:    path,
+		Detail:  "cargo " + command,
+	}
+	if group != "" {
+		task.Group = group
+	}
+	return task
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,40 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestCargoProvider_ProvideTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "Cargo.toml"), "[package]\nname = \"app\"\n")
+	writeFile(t, filepath.Join(workspaceDir, "src", "main.rs"), "fn main() {}\n")
+	writeFile(t, filepath.Join(workspaceDir, "crates", "core", "Cargo.toml"), "[package]\nname = \"core\"\n")
+	writeFile(t, filepath.Join(workspaceDir, "target", "package", "Cargo.toml"), "[package]\nname = \"app\"\n")
+
+	provider := &cargoProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	labels := make(map[string]config.Task)
+	for _, task := range tasks {
+		labels[task.Label] = task
+	}
+
+	if len(tasks) != 11 {
+		t.Errorf("expected 11 tasks, got %d", len(tasks))
+	}
+	if task, ok := labels["cargo: run"]; !ok || task.Command != "run" {
+		t.Error("expected 'cargo: run' for crate with src/main.rs")
+	}
+	if _, ok := labels["cargo: run - crates/core"]; ok {
+		t.Error("expected no run task for library crate")
+	}
+	if task, ok := labels["cargo: test - crates/core"]; !ok || task.Path != "crates/core" || task.GetGroupKind() != "test" {
+		t.Error("expected 'cargo: test - crates/core'")
+	}
+}
@@ -1,8 +1,13 @@
 package discovery
 
 import (
+	"bytes"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // FindGitRoot recursively searches for git root directory starting from the given path
@@ -22,4 +27,59 @@ func FindGitRoot(startPath string) (string, error) {
 		}
 		currentPath = parentPath
 	}
-}
\ No newline at end of file
+}
+
+// ChangedFiles lists the files that differ between base and the working tree
+// of the repository at gitRoot: changes committed since the merge base with
+// base, staged and unstaged changes, and untracked files that are not
+// ignored. Paths are relative to gitRoot and use forward slashes. An empty
+// base compares against HEAD.
+func ChangedFiles(gitRoot, base string) ([]string, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+
+	mergeBase, err := runGit(gitRoot, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	mergeBase = strings.TrimSpace(mergeBase)
+
+	diff, err := runGit(gitRoot, "diff", "--name-only", "--no-renames", "-z", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := runGit(gitRoot, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var files []string
+	for _, output := range []string{diff, untracked} {
+		for _, file := range strings.Split(output, "\x00") {
+			if file != "" && !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runGit runs a git command in dir and returns its standard output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], message)
+	}
+	return string(output), nil
+}
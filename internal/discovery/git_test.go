@@ -2,7 +2,9 @@ package discovery
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -59,4 +61,70 @@ func TestFindGitRootNotFound(t *testing.T) {
 	if err != os.ErrNotExist {
 		t.Errorf("Expected os.ErrNotExist, got %v", err)
 	}
-}
\ No newline at end of file
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(repo, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("README.md", "readme")
+	write("src/main.go", "package main")
+	write("src/old.go", "package main")
+	write(".gitignore", "*.log\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "base")
+
+	write("src/main.go", "package main // committed")
+	git("commit", "-q", "-am", "change")
+	write("src/staged.go", "package main")
+	git("add", "src/staged.go")
+	git("rm", "-q", "src/old.go")
+	write("README.md", "unstaged")
+	write("web/new.ts", "untracked")
+	write("debug.log", "ignored")
+
+	files, err := ChangedFiles(repo, "base")
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+	expected := []string{"README.md", "src/main.go", "src/old.go", "src/staged.go", "web/new.ts"}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+
+	// Without a base only uncommitted changes count
+	files, err = ChangedFiles(repo, "")
+	if err != nil {
+		t.Fatalf("ChangedFiles failed: %v", err)
+	}
+	expected = []string{"README.md", "src/old.go", "src/staged.go", "web/new.ts"}
+	if strings.Join(files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+
+	if _, err := ChangedFiles(repo, "no-such-ref"); err == nil {
+		t.Error("expected an error for an unknown base")
+	}
+}
@@ -0,0 +1,125 @@
+package discovery
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MatchAnyPattern reports whether rel matches one of the patterns, or lies
+// inside a directory matched by one of them.
+func MatchAnyPattern(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if MatchPatternOrParent(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpandPatterns returns the files below root that match one of the
+// gitignore-style patterns, as sorted slash-separated paths relative to root.
+// Directories ignored by .gitignore and similar files are skipped unless a
+// pattern names them, and the .git directory is never searched.
+func ExpandPatterns(root string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	return expandPatterns(root, patterns, NewIgnoreMatcher(root))
+}
+
+// ExpandOutputs is ExpandPatterns for the files a task writes. The patterns
+// are anchored at root, so "dist" is the dist folder of the workspace and not
+// every folder of that name. Outputs are usually ignored by git, so ignore
+// files are not consulted.
+func ExpandOutputs(root string, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	anchored := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		anchored[i] = "/" + strings.TrimPrefix(pattern, "/")
+	}
+	return expandPatterns(root, anchored, nil)
+}
+
+// expandPatterns walks only the directories the patterns can match in, from
+// the part of each pattern before its first wildcard.
+func expandPatterns(root string, patterns []string, ignore *IgnoreMatcher) ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, start := range walkStarts(patterns) {
+		startPath := filepath.Join(root, filepath.FromSlash(start))
+		// A directory named by a pattern is searched even if it is ignored
+		skipIgnored := ignore != nil && !ignore.Match(startPath, true)
+
+		err := filepath.WalkDir(startPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if path != root && errors.Is(err, fs.ErrNotExist) {
+					return nil
+				}
+				return err
+			}
+			rel, _ := RelativePath(root, path)
+			if entry.IsDir() {
+				if entry.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				if path != startPath && skipIgnored && ignore.Match(path, true) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !seen[rel] && MatchAnyPattern(patterns, rel) {
+				seen[rel] = true
+				files = append(files, rel)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(files)
+	return files, nil
+}
+
+// walkStarts returns the static prefixes of the patterns, leaving out those
+// inside another one.
+func walkStarts(patterns []string) []string {
+	prefixes := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		prefixes[i] = staticPrefix(normalizePattern(pattern))
+	}
+	sort.Strings(prefixes)
+
+	var starts []string
+	for _, prefix := range prefixes {
+		covered := false
+		for _, start := range starts {
+			if start == "" || prefix == start || strings.HasPrefix(prefix, start+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			starts = append(starts, prefix)
+		}
+	}
+	return starts
+}
+
+// staticPrefix returns the leading path segments of a normalized pattern that
+// contain no wildcards.
+func staticPrefix(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, `*?[{\`) {
+			return strings.Join(segments[:i], "/")
+		}
+	}
+	return pattern
+}
@@ -0,0 +1,90 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeGlobTestFiles(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestExpandPatterns(t *testing.T) {
+	root := t.TempDir()
+	writeGlobTestFiles(t, root, "go.mod", "main.go", "cmd/run.go", "web/src/app.ts", "web/dist/app.js", ".git/HEAD",
+		"node_modules/lib/index.go", "gen/api.go")
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("node_modules/\ngen/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		expected []string
+	}{
+		{name: "no patterns", patterns: nil, expected: nil},
+		{name: "unanchored glob", patterns: []string{"*.go"}, expected: []string{"cmd/run.go", "main.go"}},
+		{name: "directory", patterns: []string{"web/dist"}, expected: []string{"web/dist/app.js"}},
+		{name: "several patterns", patterns: []string{"go.mod", "web/**/*.ts"}, expected: []string{"go.mod", "web/src/app.ts"}},
+		{name: "git directory is skipped", patterns: []string{"HEAD"}, expected: nil},
+		{name: "ignored directory named by the pattern", patterns: []string{"gen/*.go"}, expected: []string{"gen/api.go"}},
+		{name: "missing directory", patterns: []string{"docs/**"}, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ExpandPatterns(root, tt.patterns)
+			if err != nil {
+				t.Fatalf("ExpandPatterns failed: %v", err)
+			}
+			if !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, files)
+			}
+		})
+	}
+}
+
+func TestExpandOutputs(t *testing.T) {
+	root := t.TempDir()
+	writeGlobTestFiles(t, root, "dist/app.js", "web/dist/app.js", "node_modules/lib/dist/index.js", "schema.json")
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("dist/\nnode_modules/\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ExpandOutputs(root, []string{"dist", "schema.json", "web/*/app.js"})
+	if err != nil {
+		t.Fatalf("ExpandOutputs failed: %v", err)
+	}
+	expected := []string{"dist/app.js", "schema.json", "web/dist/app.js"}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("expected %v, got %v", expected, files)
+	}
+}
+
+func TestWalkStarts(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{"/src/**/*.go", "/src/gen/*.go", "/docs"}, []string{"docs", "src"}},
+		{[]string{"*.go", "/src"}, []string{""}},
+		{[]string{"/web/{a,b}/*.ts", "/web/dist/app.js"}, []string{"web"}},
+	}
+
+	for _, tt := range tests {
+		if got := walkStarts(tt.patterns); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("walkStarts(%v) = %v, expected %v", tt.patterns, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package discovery
+
+import (
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// goProvider detects "go" tasks: "build" and "test" for every module and
+// "build"/"run" for every main package inside it.
+type goProvider struct{}
+
+func (p *goProvider) Type() string {
+	return "go"
+}
+
+func (p *goProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("go.autoDetect")
+}
+
+func (p *goProvider) FileNames() []string {
+	return []string{"go.mod"}
+}
+
+func (p *goProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	workspaceDir := workspace.Dir
+	excludes := settings.GetStringSlice("go.exclude")
+	modules := workspace.findFiles(p.FileNames(), excludes)
+
+	// Excluded modules are still left out of the modules containing them
+	moduleDirs := make(map[string]bool)
+	for _, path := range workspace.files {
+		if filepath.Base(path) == "go.mod" {
+			moduleDirs[filepath.Dir(path)] = true
+		}
+	}
+
+	var tasks []config.Task
+	for _, goMod := range modules {
+		moduleDir := filepath.Dir(goMod)
+		path := relativeDir(workspaceDir, moduleDir)
+
+		tasks = append(tasks,
+			newGoTask("build", "./...", path, "build"),
+			newGoTask("test", "./...", path, "test"),
+		)
+
+		mainPackages, err := findMainPackages(workspace, moduleDir, moduleDirs, excludes)
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range mainPackages {
+			tasks = append(tasks,
+				newGoTask("build", pkg, path, ""),
+				newGoTask("run", pkg, path, ""),
+			)
+		}
+	}
+
+	return tasks, nil
+}
+
+func newGoTask(command, pkg, path, group string) config.Task {
+	task := config.Task{
+		Label:   detectedTaskLabel("go", command+" "+pkg, path),
+		Type:    "go",
+		Command: command,
+		Args:    []string{pkg},
+		Path:    path,
+		Detail:  "go " + command + " " + pkg,
+	}
+	if group != "" {
+		task.Group = group
+	}
+	return task
+}
+
+// findMainPackages returns the "./"-prefixed import paths, relative to
+// moduleDir, of directories whose non-test Go files declare package main.
+// Only the module's own directories are searched: nested modules, vendor,
+// testdata and ignored directories are skipped. All files of a package
+// declare the same name, so one file is parsed per directory.
+func findMainPackages(workspace *Workspace, moduleDir string, moduleDirs map[string]bool, excludes []string) ([]string, error) {
+	checked := make(map[string]bool)
+	var packages []string
+	fileSet := token.NewFileSet()
+
+	err := filepath.WalkDir(moduleDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != moduleDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			if path == moduleDir {
+				return nil
+			}
+			name := d.Name()
+			if alwaysSkippedDirs[name] || name == "testdata" || moduleDirs[path] ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+				workspace.ignore.Match(path, true) || isExcludedDir(workspace.Dir, path, excludes) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		dir := filepath.Dir(path)
+		if checked[dir] || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fileSet, path, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return nil
+		}
+		checked[dir] = true
+		if file.Name.Name != "main" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(moduleDir, dir)
+		if err != nil {
+			return nil
+		}
+		if rel == "." {
+			packages = append(packages, ".")
+		} else {
+			packages = append(packages, "./"+filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(packages)
+	return packages, nil
+}
@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestGoProvider_ProvideTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(workspaceDir, "main.go"), "package main\n\nfunc main() {}\n")
+	writeFile(t, filepath.Join(workspaceDir, "cmd", "tool", "main.go"), "// Command tool\npackage main\n")
+	writeFile(t, filepath.Join(workspaceDir, "internal", "lib", "lib.go"), "package lib\n")
+	writeFile(t, filepath.Join(workspaceDir, "internal", "lib", "main_test.go"), "package main\n")
+	writeFile(t, filepath.Join(workspaceDir, "testdata", "fixture.go"), "package main\n")
+
+	provider := &goProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	expected := []string{
+		"go: build ./...",
+		"go: test ./...",
+		"go: build .",
+		"go: run .",
+		"go: build ./cmd/tool",
+		"go: run ./cmd/tool",
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("expected %d tasks, got %d: %v", len(expected), len(tasks), tasks)
+	}
+	for i, label := range expected {
+		if tasks[i].Label != label {
+			t.Errorf("task %d: expected %q, got %q", i, label, tasks[i].Label)
+		}
+	}
+
+	if tasks[1].Command != "test" || tasks[1].Args[0] != "./..." || tasks[1].GetGroupKind() != "test" {
+		t.Errorf("unexpected test task: %+v", tasks[1])
+	}
+}
+
+func TestGoProvider_NestedModule(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "go.mod"), "module example.com/root\n")
+	writeFile(t, filepath.Join(workspaceDir, "tools", "go.mod"), "module example.com/tools\n")
+	writeFile(t, filepath.Join(workspaceDir, "tools", "gen", "main.go"), "package main\n")
+
+	provider := &goProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	var labels []string
+	for _, task := range tasks {
+		labels = append(labels, task.Label)
+	}
+	if len(tasks) != 6 {
+		t.Fatalf("expected 6 tasks, got %v", labels)
+	}
+	if tasks[4].Label != "go: build ./gen - tools" || tasks[4].Path != "tools" {
+		t.Errorf("expected main package to belong to nested module, got %v", labels)
+	}
+}
+
+func TestGoProvider_SkipsIgnoredDirs(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, ".gitignore"), "/out/\n")
+	writeFile(t, filepath.Join(workspaceDir, "go.mod"), "module example.com/app\n")
+	writeFile(t, filepath.Join(workspaceDir, "cmd", "app", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(workspaceDir, "out", "gen", "main.go"), "package main\n")
+	writeFile(t, filepath.Join(workspaceDir, "vendor", "tool", "main.go"), "package main\n")
+
+	provider := &goProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	var labels []string
+	for _, task := range tasks {
+		labels = append(labels, task.Label)
+	}
+	if len(tasks) != 4 || tasks[2].Label != "go: build ./cmd/app" {
+		t.Errorf("expected only ./cmd/app as main package, got %v", labels)
+	}
+}
@@ -0,0 +1,138 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+var gulpFileNames = []string{
+	"gulpfile.js", "gulpfile.mjs", "gulpfile.cjs", "gulpfile.ts", "gulpfile.babel.js",
+	"Gulpfile.js", "Gulpfile.mjs", "Gulpfile.cjs", "Gulpfile.ts",
+}
+
+var gruntFileNames = []string{"Gruntfile.js", "gruntfile.js", "Gruntfile.coffee", "gruntfile.coffee"}
+
+// gulpTaskPatterns match gulp 3 style gulp.task() calls and gulp 4 exports.
+var gulpTaskPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`gulp\.task\(\s*['"]([^'"]+)['"]`),
+	regexp.MustCompile(`(?m)^\s*exports\.([A-Za-z0-9_$]+)\s*=`),
+	regexp.MustCompile(`(?m)^\s*export\s+(?:async\s+)?(?:function\*?|const|let|var)\s+([A-Za-z0-9_$]+)`),
+	regexp.MustCompile(`(?m)^\s*export\s+(default)\b`),
+	regexp.MustCompile(`(?m)^\s*module\.exports\.([A-Za-z0-9_$]+)\s*=`),
+}
+
+// gruntTaskPatterns match grunt.registerTask() and registerMultiTask() calls.
+var gruntTaskPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`grunt\.register(?:Multi)?Task\(\s*['"]([^'"]+)['"]`),
+}
+
+// gulpProvider detects "gulp" tasks from gulpfiles.
+type gulpProvider struct{}
+
+func (p *gulpProvider) Type() string {
+	return "gulp"
+}
+
+func (p *gulpProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("gulp.autoDetect")
+}
+
+func (p *gulpProvider) FileNames() []string {
+	return gulpFileNames
+}
+
+func (p *gulpProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	return provideScriptedTasks("gulp", workspace, gulpFileNames, gulpTaskPatterns, settings.GetStringSlice("gulp.exclude"))
+}
+
+// gruntProvider detects "grunt" tasks from Gruntfiles.
+type gruntProvider struct{}
+
+func (p *gruntProvider) Type() string {
+	return "grunt"
+}
+
+func (p *gruntProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("grunt.autoDetect")
+}
+
+func (p *gruntProvider) FileNames() []string {
+	return gruntFileNames
+}
+
+func (p *gruntProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	return provideScriptedTasks("grunt", workspace, gruntFileNames, gruntTaskPatterns, settings.GetStringSlice("grunt.exclude"))
+}
+
+// provideScriptedTasks extracts task names from JavaScript task runner files.
+func provideScriptedTasks(taskType string, workspace *Workspace, fileNames []string, patterns []*regexp.Regexp, excludes []string) ([]config.Task, error) {
+	workspaceDir := workspace.Dir
+	files := workspace.findFiles(fileNames, excludes)
+
+	var tasks []config.Task
+	var errs []error
+	for _, taskFile := range files {
+		data, err := os.ReadFile(taskFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", taskFile, err))
+			continue
+		}
+
+		path := relativeDir(workspaceDir, filepath.Dir(taskFile))
+		for _, name := range parseTaskNames(data, patterns) {
+			task := config.Task{
+				Label:    detectedTaskLabel(taskType, name, path),
+				Type:     taskType,
+				TaskName: name,
+				File:     filepath.Base(taskFile),
+				Path:     path,
+				Detail:   taskType + " " + name,
+			}
+			switch name {
+			case "build", "default":
+				task.Group = "build"
+			case "test":
+				task.Group = "test"
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, errors.Join(errs...)
+}
+
+// parseTaskNames returns the unique names captured by patterns in the order
+// they appear in the file.
+func parseTaskNames(data []byte, patterns []*regexp.Regexp) []string {
+	type match struct {
+		offset int
+		name   string
+	}
+	var matches []match
+	for _, pattern := range patterns {
+		for _, loc := range pattern.FindAllSubmatchIndex(data, -1) {
+			matches = append(matches, match{offset: loc[2], name: string(data[loc[2]:loc[3]])})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].offset < matches[j].offset
+	})
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range matches {
+		if seen[m.name] {
+			continue
+		}
+		seen[m.name] = true
+		names = append(names, m.name)
+	}
+	return names
+}
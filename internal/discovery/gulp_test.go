@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestParseTaskNames_Gulp(t *testing.T) {
+	gulpfile := `
+const gulp = require('gulp');
+
+gulp.task('styles', function () {});
+gulp.task("scripts", ['styles'], function () {});
+
+exports.build = gulp.series(styles, scripts);
+exports.default = exports.build;
+
+export async function lint() {}
+`
+	expected := []string{"styles", "scripts", "build", "default", "lint"}
+	if got := parseTaskNames([]byte(gulpfile), gulpTaskPatterns); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseTaskNames() = %v, expected %v", got, expected)
+	}
+}
+
+func TestParseTaskNames_Grunt(t *testing.T) {
+	gruntfile := `
+module.exports = function (grunt) {
+  grunt.registerTask('default', ['jshint', 'uglify']);
+  grunt.registerMultiTask("concat", "Concatenate files.", function () {});
+};
+`
+	expected := []string{"default", "concat"}
+	if got := parseTaskNames([]byte(gruntfile), gruntTaskPatterns); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseTaskNames() = %v, expected %v", got, expected)
+	}
+}
+
+func TestGulpProvider_ProvideTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "gulpfile.js"), "exports.build = build;\nexports.test = test;\n")
+
+	provider := &gulpProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Label != "gulp: build" || tasks[0].Type != "gulp" || tasks[0].TaskName != "build" || tasks[0].File != "gulpfile.js" {
+		t.Errorf("unexpected gulp task: %+v", tasks[0])
+	}
+
+	settings := &config.Settings{}
+	settings.Set("gulp.autoDetect", false)
+	if provider.Enabled(settings) {
+		t.Error("expected gulp detection to be disabled")
+	}
+}
@@ -0,0 +1,210 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ignoreFileNames are read from every directory, later files taking precedence.
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+type ignoreRule struct {
+	// base is the directory of the ignore file, relative to the root
+	base    string
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// IgnoreMatcher reports whether paths below a root directory are ignored by
+// .gitignore, .ignore and .git/info/exclude rules. Ignore files of nested
+// directories are loaded on demand.
+type IgnoreMatcher struct {
+	root   string
+	mu     sync.Mutex
+	rules  []ignoreRule
+	loaded map[string]bool
+}
+
+// NewIgnoreMatcher creates a matcher for the tree rooted at root.
+func NewIgnoreMatcher(root string) *IgnoreMatcher {
+	m := &IgnoreMatcher{root: root, loaded: make(map[string]bool)}
+	m.addRules("", readIgnoreFile(filepath.Join(root, ".git", "info", "exclude")))
+	return m
+}
+
+// Match reports whether path is ignored. Paths outside the root never are.
+func (m *IgnoreMatcher) Match(path string, isDir bool) bool {
+	rel, ok := RelativePath(m.root, path)
+	if !ok || rel == "" {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Like git, nothing inside an ignored directory can be re-included
+	segments := strings.Split(rel, "/")
+	for i := range segments {
+		dir := strings.Join(segments[:i], "/")
+		m.loadDir(dir)
+		current := strings.Join(segments[:i+1], "/")
+		if m.matchRules(current, isDir || i < len(segments)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *IgnoreMatcher) loadDir(dir string) {
+	if m.loaded[dir] {
+		return
+	}
+	m.loaded[dir] = true
+	for _, name := range ignoreFileNames {
+		m.addRules(dir, readIgnoreFile(filepath.Join(m.root, filepath.FromSlash(dir), name)))
+	}
+}
+
+func (m *IgnoreMatcher) addRules(base string, lines []string) {
+	for _, line := range lines {
+		if rule, ok := parseIgnoreLine(base, line); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+	// Rules of deeper directories override those of their parents
+	sort.SliceStable(m.rules, func(i, j int) bool {
+		return pathDepth(m.rules[i].base) < pathDepth(m.rules[j].base)
+	})
+}
+
+func (m *IgnoreMatcher) matchRules(rel string, isDir bool) bool {
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		target := rel
+		if rule.base != "" {
+			if !strings.HasPrefix(rel, rule.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(rel, rule.base+"/")
+		}
+		if matched, _ := doublestar.Match(rule.pattern, target); matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// MatchPattern reports whether the slash-separated relative path matches a
+// gitignore-style glob: patterns without a slash match a file or directory
+// name at any depth, other patterns are anchored to the root. "**" matches
+// any number of directories.
+func MatchPattern(pattern, rel string) bool {
+	matched, _ := doublestar.Match(normalizePattern(pattern), rel)
+	return matched
+}
+
+// MatchPatternOrParent is like MatchPattern, but also matches paths inside a
+// matching directory.
+func MatchPatternOrParent(pattern, rel string) bool {
+	pattern = normalizePattern(pattern)
+	for {
+		if matched, _ := doublestar.Match(pattern, rel); matched {
+			return true
+		}
+		index := strings.LastIndex(rel, "/")
+		if index < 0 {
+			return false
+		}
+		rel = rel[:index]
+	}
+}
+
+// ValidatePattern reports whether pattern is a well-formed glob.
+func ValidatePattern(pattern string) bool {
+	return pattern != "" && doublestar.ValidatePattern(normalizePattern(pattern))
+}
+
+func normalizePattern(pattern string) string {
+	pattern = strings.TrimSuffix(pattern, "/")
+	if strings.HasPrefix(pattern, "/") {
+		return strings.TrimPrefix(pattern, "/")
+	}
+	if !strings.Contains(pattern, "/") {
+		return "**/" + pattern
+	}
+	return pattern
+}
+
+func parseIgnoreLine(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: base}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+	}
+	rule.pattern = normalizePattern(line)
+	if rule.pattern == "" || rule.pattern == "**/" {
+		return ignoreRule{}, false
+	}
+	return rule, true
+}
+
+func readIgnoreFile(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+// RelativePath returns path relative to root with forward slashes, and false
+// if path is outside of root. Relative paths are taken as relative to root.
+func RelativePath(root, path string) (string, bool) {
+	if !filepath.IsAbs(path) || root == "" {
+		rel := filepath.ToSlash(filepath.Clean(path))
+		if rel == "." {
+			rel = ""
+		}
+		return rel, !strings.HasPrefix(rel, "../")
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if rel == "." {
+		return "", true
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func pathDepth(rel string) int {
+	if rel == "" {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
@@ -0,0 +1,84 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".gitignore"), "# build output\ndist/\n*.log\n!keep.log\n/coverage\n")
+	writeFile(t, filepath.Join(root, ".ignore"), "fixtures/**/*.json\n")
+	writeFile(t, filepath.Join(root, ".git", "info", "exclude"), "scratch.txt\n")
+	writeFile(t, filepath.Join(root, "web", ".gitignore"), ".cache\n!debug.log\n")
+
+	m := NewIgnoreMatcher(root)
+
+	tests := []struct {
+		path     string
+		isDir    bool
+		expected bool
+	}{
+		{"dist", true, true},
+		{"dist/app.js", false, true},
+		{"pkg/dist", true, true},
+		{"dist", false, false},
+		{"server.log", false, true},
+		{"logs/server.log", false, true},
+		{"keep.log", false, false},
+		{"coverage", true, true},
+		{"pkg/coverage", true, false},
+		{"fixtures/a/b.json", false, true},
+		{"fixtures/a/b.yaml", false, false},
+		{"scratch.txt", false, true},
+		{"web/.cache", true, true},
+		{"web/.cache/x", false, true},
+		{".cache", true, false},
+		{"web/debug.log", false, false},
+		{"web/other.log", false, true},
+		{"src/main.go", false, false},
+		{"src/buildinfo.go", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := m.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.expected {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.expected)
+			}
+		})
+	}
+
+	if m.Match(filepath.Join(filepath.Dir(root), "dist"), true) {
+		t.Error("expected paths outside the root not to be ignored")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		rel      string
+		expected bool
+	}{
+		{"build", "build", true},
+		{"build", "src/build", true},
+		{"build", "src/buildinfo.go", false},
+		{"*.go", "cmd/main.go", true},
+		{"src/**/*.ts", "src/a/b/c.ts", true},
+		{"src/**/*.ts", "lib/a.ts", false},
+		{"/dist", "dist", true},
+		{"/dist", "web/dist", false},
+	}
+
+	for _, tt := range tests {
+		if got := MatchPattern(tt.pattern, tt.rel); got != tt.expected {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.expected)
+		}
+	}
+
+	if !MatchPatternOrParent("build", "build/out/app.js") {
+		t.Error("expected files inside a matching directory to match")
+	}
+	if MatchPatternOrParent("build", "src/buildinfo.go") {
+		t.Error("expected a partial name not to match")
+	}
+}
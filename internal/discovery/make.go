@@ -0,0 +1,103 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// makeFileNames are the makefile names GNU make looks for, in its order.
+var makeFileNames = []string{"GNUmakefile", "makefile", "Makefile"}
+
+// makeTargetPattern matches rule lines such as "build test: deps".
+// Variable assignments (":=", "::=") are rejected by the negative check below.
+var makeTargetPattern = regexp.MustCompile(`^([A-Za-z0-9_][A-Za-z0-9_./ -]*?)\s*::?(?:\s|$|[^=])`)
+
+// makeProvider detects "make" tasks from Makefile targets.
+type makeProvider struct{}
+
+func (p *makeProvider) Type() string {
+	return "make"
+}
+
+func (p *makeProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("make.autoDetect")
+}
+
+func (p *makeProvider) FileNames() []string {
+	return makeFileNames
+}
+
+func (p *makeProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	workspaceDir := workspace.Dir
+	makefiles := workspace.findFiles(makeFileNames, settings.GetStringSlice("make.exclude"))
+
+	var tasks []config.Task
+	var errs []error
+	for _, makefile := range makefiles {
+		data, err := os.ReadFile(makefile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", makefile, err))
+			continue
+		}
+
+		path := relativeDir(workspaceDir, filepath.Dir(makefile))
+		for _, target := range parseMakeTargets(data) {
+			task := config.Task{
+				Label:  detectedTaskLabel("make", target, path),
+				Type:   "make",
+				Target: target,
+				Path:   path,
+				Detail: "make " + target,
+			}
+			switch target {
+			case "build", "all":
+				task.Group = "build"
+			case "test", "check":
+				task.Group = "test"
+			}
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks, errors.Join(errs...)
+}
+
+// parseMakeTargets returns the explicit targets of a makefile in file order.
+// Special targets (".PHONY"), pattern rules and recipe lines are skipped.
+func parseMakeTargets(data []byte) []string {
+	seen := make(map[string]bool)
+	var targets []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.Contains(line, ":=") || strings.Contains(line, "::=") {
+			continue
+		}
+
+		match := makeTargetPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		for _, target := range strings.Fields(match[1]) {
+			if strings.HasPrefix(target, ".") || strings.Contains(target, "%") || seen[target] {
+				continue
+			}
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	return targets
+}
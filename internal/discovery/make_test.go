@@ -0,0 +1,69 @@
+package discovery
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestParseMakeTargets(t *testing.T) {
+	makefile := `
+# Build settings
+GO := go
+VERSION ?= dev
+LDFLAGS = -X main.version=$(VERSION)
+
+.PHONY: all build test clean
+
+all: build test
+
+build:
+	$(GO) build -ldflags "$(LDFLAGS)" ./...
+
+test: build
+	$(GO) test ./...
+
+%.o: %.c
+	cc -c $<
+
+lint vet::
+	golangci-lint run
+
+clean:
+	rm -rf bin/
+`
+	expected := []string{"all", "build", "test", "lint", "vet", "clean"}
+	if got := parseMakeTargets([]byte(makefile)); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseMakeTargets() = %v, expected %v", got, expected)
+	}
+}
+
+func TestMakeProvider_ProvideTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "Makefile"), "build:\n\tgo build\n")
+	writeFile(t, filepath.Join(workspaceDir, "docs", "makefile"), "html:\n\tsphinx-build . _build\n")
+
+	provider := &makeProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d: %v", len(tasks), tasks)
+	}
+	if tasks[0].Label != "make: build" || tasks[0].Target != "build" || tasks[0].GetGroupKind() != "build" {
+		t.Errorf("unexpected root task: %+v", tasks[0])
+	}
+	if tasks[1].Label != "make: html - docs" || tasks[1].Path != "docs" {
+		t.Errorf("unexpected nested task: %+v", tasks[1])
+	}
+
+	settings := &config.Settings{}
+	settings.Set("make.autoDetect", "off")
+	if provider.Enabled(settings) {
+		t.Error("expected make detection to be disabled")
+	}
+}
@@ -0,0 +1,125 @@
+package discovery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// npmProvider synthesizes "npm" tasks from the scripts of every package.json
+// in the workspace, mirroring VS Code's npm task detection.
+type npmProvider struct{}
+
+func (p *npmProvider) Type() string {
+	return "npm"
+}
+
+func (p *npmProvider) Enabled(settings *config.Settings) bool {
+	return settings.IsAutoDetectEnabled("npm.autoDetect")
+}
+
+func (p *npmProvider) FileNames() []string {
+	return []string{"package.json"}
+}
+
+func (p *npmProvider) ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error) {
+	workspaceDir := workspace.Dir
+	packageFiles := workspace.findFiles(p.FileNames(), settings.GetStringSlice("npm.exclude"))
+
+	var tasks []config.Task
+	var errs []error
+	for _, packageFile := range packageFiles {
+		data, err := os.ReadFile(packageFile)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", packageFile, err))
+			continue
+		}
+
+		scripts, err := parsePackageScripts(data)
+		if err != nil {
+			// A broken package.json should not prevent other tasks from loading
+			continue
+		}
+
+		path := relativeDir(workspaceDir, filepath.Dir(packageFile))
+		for _, script := range scripts {
+			tasks = append(tasks, newNpmTask(script.name, script.command, path))
+		}
+		tasks = append(tasks, newNpmTask("install", "install dependencies from package", path))
+	}
+
+	return tasks, errors.Join(errs...)
+}
+
+// NpmTaskLabel returns the label VS Code gives to a detected npm script.
+// Scripts of nested package.json files are suffixed with their folder.
+func NpmTaskLabel(script, path string) string {
+	return detectedTaskLabel("npm", script, path)
+}
+
+func newNpmTask(script, detail, path string) config.Task {
+	task := config.Task{
+		Label:  NpmTaskLabel(script, path),
+		Type:   "npm",
+		Script: script,
+		Path:   path,
+		Detail: detail,
+	}
+
+	switch script {
+	case "build":
+		task.Group = "build"
+	case "test":
+		task.Group = "test"
+	}
+
+	return task
+}
+
+type packageScript struct {
+	name    string
+	command string
+}
+
+// parsePackageScripts returns the "scripts" entries of a package.json in the
+// order they appear in the file.
+func parsePackageScripts(data []byte) ([]packageScript, error) {
+	var pkg struct {
+		Scripts json.RawMessage `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	if len(pkg.Scripts) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(pkg.Scripts))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("scripts must be an object")
+	}
+
+	var scripts []packageScript
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var command string
+		if err := decoder.Decode(&command); err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, packageScript{name: keyToken.(string), command: command})
+	}
+
+	return scripts, nil
+}
@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNpmProvider_ProvideTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "package.json"), `{
+		"name": "root",
+		"scripts": {"lint": "eslint .", "build": "tsc", "test": "jest"}
+	}`)
+	writeFile(t, filepath.Join(workspaceDir, "packages", "web", "package.json"), `{
+		"scripts": {"dev": "vite"}
+	}`)
+	writeFile(t, filepath.Join(workspaceDir, "node_modules", "dep", "package.json"), `{
+		"scripts": {"postinstall": "node install.js"}
+	}`)
+	writeFile(t, filepath.Join(workspaceDir, "examples", "lib", "package.json"), `{
+		"scripts": {"build": "make"}
+	}`)
+
+	settings := &config.Settings{}
+	settings.Set("npm.exclude", "examples")
+
+	provider := &npmProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), settings)
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+
+	var labels []string
+	for _, task := range tasks {
+		labels = append(labels, task.Label)
+	}
+	expected := []string{
+		"npm: lint",
+		"npm: build",
+		"npm: test",
+		"npm: install",
+		"npm: dev - packages/web",
+		"npm: install - packages/web",
+	}
+	if len(labels) != len(expected) {
+		t.Fatalf("expected labels %v, got %v", expected, labels)
+	}
+	for i := range expected {
+		if labels[i] != expected[i] {
+			t.Errorf("label %d: expected %q, got %q", i, expected[i], labels[i])
+		}
+	}
+
+	build := tasks[1]
+	if build.Type != "npm" || build.Script != "build" || build.Path != "" {
+		t.Errorf("unexpected build task: %+v", build)
+	}
+	if build.GetGroupKind() != "build" {
+		t.Errorf("expected build group, got %q", build.GetGroupKind())
+	}
+	if build.Detail != "tsc" {
+		t.Errorf("expected detail 'tsc', got %q", build.Detail)
+	}
+
+	dev := tasks[4]
+	if dev.Path != "packages/web" {
+		t.Errorf("expected path 'packages/web', got %q", dev.Path)
+	}
+}
+
+func TestNpmProvider_InvalidPackageJSON(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "package.json"), `{"scripts": {`)
+
+	provider := &npmProvider{}
+	tasks, err := provider.ProvideTasks(scanWorkspace(t, workspaceDir, provider), &config.Settings{})
+	if err != nil {
+		t.Fatalf("ProvideTasks failed: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected no tasks for invalid package.json, got %d", len(tasks))
+	}
+}
+
+func TestNpmProvider_Enabled(t *testing.T) {
+	provider := &npmProvider{}
+	settings := &config.Settings{}
+	if !provider.Enabled(settings) {
+		t.Error("expected npm detection to be enabled by default")
+	}
+
+	settings.Set("npm.autoDetect", "off")
+	if provider.Enabled(settings) {
+		t.Error("expected npm detection to be disabled")
+	}
+}
+
+func TestNpmTaskLabel(t *testing.T) {
+	if got := NpmTaskLabel("build", ""); got != "npm: build" {
+		t.Errorf("unexpected label %q", got)
+	}
+	if got := NpmTaskLabel("build", "packages/api"); got != "npm: build - packages/api" {
+		t.Errorf("unexpected label %q", got)
+	}
+}
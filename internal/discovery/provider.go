@@ -0,0 +1,240 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// TaskProvider detects tasks from workspace files, like VS Code's task providers.
+type TaskProvider interface {
+	// Type returns the task type of the detected tasks (e.g. "npm").
+	Type() string
+	// Enabled reports whether detection is turned on in the workspace settings.
+	Enabled(settings *config.Settings) bool
+	// FileNames returns the names of the files the tasks are detected from.
+	FileNames() []string
+	// ProvideTasks returns the tasks detected from the scanned workspace. Files
+	// that cannot be read are skipped and reported in the error, which may be
+	// returned together with the tasks of the other files.
+	ProvideTasks(workspace *Workspace, settings *config.Settings) ([]config.Task, error)
+}
+
+// alwaysSkippedDirs are never scanned by providers, nor are directories
+// ignored by .gitignore and similar files.
+var alwaysSkippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+var taskProviders = []TaskProvider{
+	&npmProvider{},
+	&makeProvider{},
+	&goProvider{},
+	&cargoProvider{},
+	&gulpProvider{},
+	&gruntProvider{},
+}
+
+// TaskProviders returns all registered task providers.
+func TaskProviders() []TaskProvider {
+	return taskProviders
+}
+
+// DetectTasks runs every enabled task provider against the workspace, which
+// is scanned once for all of them. The errors of the providers are returned
+// together with the tasks that were detected.
+func DetectTasks(workspaceDir string, settings *config.Settings) ([]config.Task, error) {
+	var enabled []TaskProvider
+	var fileNames []string
+	for _, provider := range taskProviders {
+		if provider.Enabled(settings) {
+			enabled = append(enabled, provider)
+			fileNames = append(fileNames, provider.FileNames()...)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	workspace, err := ScanWorkspace(workspaceDir, fileNames)
+	if err != nil {
+		return nil, fmt.Errorf("task detection failed: %w", err)
+	}
+
+	var detected []config.Task
+	var errs []error
+	for _, provider := range enabled {
+		tasks, err := provider.ProvideTasks(workspace, settings)
+		for _, fileErr := range splitErrors(err) {
+			errs = append(errs, fmt.Errorf("%s task detection: %w", provider.Type(), fileErr))
+		}
+		detected = append(detected, tasks...)
+	}
+	return detected, errors.Join(errs...)
+}
+
+// splitErrors returns the errors joined in err, or err itself.
+func splitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+// MergeTasks appends detected tasks to the configured ones. Tasks defined in
+// tasks.json take precedence over detected tasks with the same label.
+func MergeTasks(configured, detected []config.Task) []config.Task {
+	labels := make(map[string]bool, len(configured))
+	merged := make([]config.Task, 0, len(configured)+len(detected))
+	for _, task := range configured {
+		labels[task.Label] = true
+		merged = append(merged, task)
+	}
+	for _, task := range detected {
+		if labels[task.Label] {
+			continue
+		}
+		labels[task.Label] = true
+		merged = append(merged, task)
+	}
+	return merged
+}
+
+// FindSettingsFile returns the settings.json path that sits next to tasksPath.
+func FindSettingsFile(tasksPath string) string {
+	return filepath.Join(filepath.Dir(tasksPath), "settings.json")
+}
+
+// Workspace holds the files task providers detect tasks from, collected in a
+// single walk of the workspace folder.
+type Workspace struct {
+	Dir string
+	// files are the paths of the collected files in walk order
+	files  []string
+	ignore *IgnoreMatcher
+}
+
+// ScanWorkspace walks workspaceDir and collects the files named one of
+// fileNames. Directories in alwaysSkippedDirs and directories ignored by git
+// are not searched.
+func ScanWorkspace(workspaceDir string, fileNames []string) (*Workspace, error) {
+	wanted := make(map[string]bool, len(fileNames))
+	for _, name := range fileNames {
+		wanted[name] = true
+	}
+	ignore := NewIgnoreMatcher(workspaceDir)
+	workspace := &Workspace{Dir: workspaceDir, ignore: ignore}
+
+	err := filepath.WalkDir(workspaceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsPermission(err) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+
+		if d.IsDir() {
+			if path != workspaceDir && (alwaysSkippedDirs[d.Name()] || ignore.Match(path, true)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if wanted[d.Name()] {
+			workspace.files = append(workspace.files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// findFiles returns the paths of the files named one of fileNames, leaving
+// out those in directories that match one of the exclude globs. Globs are
+// matched against directory paths relative to the workspace. At most one file
+// is returned per directory, preferring earlier names.
+func (w *Workspace) findFiles(fileNames []string, excludes []string) []string {
+	var found []string
+	foundDirs := make(map[string]int)
+
+	for _, path := range w.files {
+		name := filepath.Base(path)
+		priority := fileNamePriority(fileNames, name)
+		if priority == len(fileNames) {
+			continue
+		}
+		dir := filepath.Dir(path)
+		if isExcludedDir(w.Dir, dir, excludes) {
+			continue
+		}
+		if index, ok := foundDirs[dir]; ok {
+			if priority < fileNamePriority(fileNames, filepath.Base(found[index])) {
+				found[index] = path
+			}
+			continue
+		}
+		foundDirs[dir] = len(found)
+		found = append(found, path)
+	}
+	return found
+}
+
+func fileNamePriority(fileNames []string, name string) int {
+	for i, fileName := range fileNames {
+		if fileName == name {
+			return i
+		}
+	}
+	return len(fileNames)
+}
+
+// isExcludedDir reports whether dir or one of its parents below the workspace
+// matches one of the exclude globs.
+func isExcludedDir(workspaceDir, dir string, excludes []string) bool {
+	rel := relativeDir(workspaceDir, dir)
+	for rel != "" {
+		for _, pattern := range excludes {
+			if matched, _ := doublestar.Match(pattern, rel); matched {
+				return true
+			}
+		}
+		index := strings.LastIndex(rel, "/")
+		if index < 0 {
+			break
+		}
+		rel = rel[:index]
+	}
+	return false
+}
+
+// detectedTaskLabel builds a "<type>: <name>" label, suffixed with the folder
+// for files that are not at the workspace root.
+func detectedTaskLabel(taskType, name, path string) string {
+	if path == "" {
+		return fmt.Sprintf("%s: %s", taskType, name)
+	}
+	return fmt.Sprintf("%s: %s - %s", taskType, name, path)
+}
+
+// relativeDir returns dir relative to the workspace using forward slashes,
+// or an empty string for the workspace root itself.
+func relativeDir(workspaceDir, dir string) string {
+	rel, err := filepath.Rel(workspaceDir, dir)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
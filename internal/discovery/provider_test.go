@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// scanWorkspace scans workspaceDir for the files of provider.
+func scanWorkspace(t *testing.T, workspaceDir string, provider TaskProvider) *Workspace {
+	t.Helper()
+	workspace, err := ScanWorkspace(workspaceDir, provider.FileNames())
+	if err != nil {
+		t.Fatalf("ScanWorkspace failed: %v", err)
+	}
+	return workspace
+}
+
+func TestMergeTasks(t *testing.T) {
+	configured := []config.Task{
+		{Label: "build", Type: "shell", Command: "make"},
+		{Label: "npm: test", Type: "shell", Command: "npm test -- --ci"},
+	}
+	detected := []config.Task{
+		{Label: "npm: test", Type: "npm", Script: "test"},
+		{Label: "npm: lint", Type: "npm", Script: "lint"},
+	}
+
+	merged := MergeTasks(configured, detected)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 tasks, got %d", len(merged))
+	}
+	if merged[1].Type != "shell" {
+		t.Error("expected tasks.json definition to take precedence over detected task")
+	}
+	if merged[2].Label != "npm: lint" {
+		t.Errorf("expected detected task to be appended, got %q", merged[2].Label)
+	}
+}
+
+func TestDetectTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "package.json"), `{"scripts": {"start": "node index.js"}}`)
+
+	tasks, err := DetectTasks(workspaceDir, &config.Settings{})
+	if err != nil {
+		t.Fatalf("DetectTasks failed: %v", err)
+	}
+
+	found := false
+	for _, task := range tasks {
+		if task.Label == "npm: start" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected 'npm: start' to be detected")
+	}
+
+	settings := &config.Settings{}
+	settings.Set("npm.autoDetect", "off")
+	tasks, err = DetectTasks(workspaceDir, settings)
+	if err != nil {
+		t.Fatalf("DetectTasks failed: %v", err)
+	}
+	if len(tasks) != 0 {
+		t.Errorf("expected no tasks with detection disabled, got %d", len(tasks))
+	}
+}
+
+func TestDetectTasks_SkipsIgnoredDirs(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, ".gitignore"), "build/\n")
+	writeFile(t, filepath.Join(workspaceDir, "Makefile"), "all:\n\techo all\n")
+	writeFile(t, filepath.Join(workspaceDir, "build", "Makefile"), "generated:\n\techo generated\n")
+	writeFile(t, filepath.Join(workspaceDir, "vendor", "lib", "package.json"), `{"scripts": {"build": "make"}}`)
+
+	tasks, err := DetectTasks(workspaceDir, &config.Settings{})
+	if err != nil {
+		t.Fatalf("DetectTasks failed: %v", err)
+	}
+	var labels []string
+	for _, task := range tasks {
+		labels = append(labels, task.Label)
+	}
+	if len(labels) != 1 || labels[0] != "make: all" {
+		t.Errorf("expected only the root Makefile to be detected, got %v", labels)
+	}
+}
+
+func TestDetectTasks_UnreadableFile(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeFile(t, filepath.Join(workspaceDir, "package.json"), `{"scripts": {"build": "tsc"}}`)
+	if err := os.MkdirAll(filepath.Join(workspaceDir, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("missing.js", filepath.Join(workspaceDir, "web", "gulpfile.js")); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks, err := DetectTasks(workspaceDir, &config.Settings{})
+	if err == nil || !strings.Contains(err.Error(), "gulp task detection: failed to read") {
+		t.Errorf("expected the unreadable gulpfile to be reported, got %v", err)
+	}
+	found := false
+	for _, task := range tasks {
+		if task.Label == "npm: build" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the tasks of readable files to be kept, got %v", tasks)
+	}
+}
+
+func TestDetectTasks_AllDisabled(t *testing.T) {
+	// A missing workspace fails the scan, so no error means no scan
+	settings := &config.Settings{}
+	for _, provider := range TaskProviders() {
+		settings.Set(provider.Type()+".autoDetect", "off")
+	}
+	tasks, err := DetectTasks(filepath.Join(t.TempDir(), "missing"), settings)
+	if err != nil || len(tasks) != 0 {
+		t.Errorf("expected no detection with every provider disabled, got %v, %v", tasks, err)
+	}
+}
+
+func TestIsExcludedDir(t *testing.T) {
+	root := filepath.Join("workspace")
+	tests := []struct {
+		dir      string
+		expected bool
+	}{
+		{"examples", true},
+		{filepath.Join("examples", "lib"), true},
+		{"src", false},
+		{filepath.Join("src", "examples"), false},
+	}
+	for _, tt := range tests {
+		if got := isExcludedDir(root, filepath.Join(root, tt.dir), []string{"examples"}); got != tt.expected {
+			t.Errorf("isExcludedDir(%s) = %v, want %v", tt.dir, got, tt.expected)
+		}
+	}
+}
+
+func TestFindSettingsFile(t *testing.T) {
+	tasksPath := filepath.Join("project", ".vscode", "tasks.json")
+	expected := filepath.Join("project", ".vscode", "settings.json")
+	if got := FindSettingsFile(tasksPath); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,346 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+)
+
+// cacheVersion is part of every cache key, so that changes to the key or the
+// entry layout invalidate old entries.
+const cacheVersion = "2"
+
+// Cache stores the results of cacheable tasks on disk. Each entry is a
+// directory named after the cache key that holds the task's output files and
+// its logged stdout.
+type Cache struct {
+	dir string
+}
+
+// CacheEntry describes a stored task result.
+type CacheEntry struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	Created  time.Time     `json:"created"`
+	Duration time.Duration `json:"duration"`
+	Outputs  []string      `json:"outputs,omitempty"`
+	// Size is the disk usage of the entry in bytes
+	Size int64 `json:"-"`
+}
+
+// NewCache returns a cache that keeps its entries in dir.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultCacheDir returns the cache directory of a workspace inside the
+// user's cache directory, e.g. $XDG_CACHE_HOME/tasks-json-cli/<hash>.
+func DefaultCacheDir(workspaceDir string) (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	absolute, err := filepath.Abs(workspaceDir)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(absolute))
+	return filepath.Join(base, "tasks-json-cli", hex.EncodeToString(sum[:8])), nil
+}
+
+// Dir returns the directory of the cache.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// IsCacheable reports whether a task opted into caching with "x-cache".
+// Background tasks never finish, so they are never cached.
+func IsCacheable(task *config.Task) bool {
+	return task.Cache && !IsBackgroundTask(task)
+}
+
+// CacheKey computes the cache key of a task from the current state of the
+// workspace, as configured by opts.
+func CacheKey(task *config.Task, allTasks []config.Task, opts RunOptions) (string, error) {
+	return cacheKey(task, NewDependencyResolver(allTasks), opts, map[string]string{})
+}
+
+// cacheKey hashes the task's definition after variable substitution, which
+// determines its command line, working directory and environment, the
+// contents of its input files and the results of its dependencies: the keys
+// of cacheable dependencies and the contents of the output files of the
+// others. Keys are memoized by label in keys.
+func cacheKey(task *config.Task, resolver *DependencyResolver, opts RunOptions, keys map[string]string) (string, error) {
+	if key, ok := keys[task.Label]; ok {
+		return key, nil
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "version %s %s/%s\n", cacheVersion, runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(hash, "label %q\n", task.Label)
+
+	// The command is not resolved here, as plugins would have to be started
+	definition, err := substitutedDefinition(task, opts.WorkspaceDir, opts.File)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := json.Marshal(definition)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(hash, "definition %s\n", encoded)
+	if task.PackageManager != "" {
+		fmt.Fprintf(hash, "packageManager %q\n", task.PackageManager)
+	}
+
+	inputs, err := discovery.ExpandPatterns(opts.WorkspaceDir, task.Inputs)
+	if err != nil {
+		return "", err
+	}
+	if err := hashFiles(hash, "input", opts.WorkspaceDir, inputs); err != nil {
+		return "", err
+	}
+
+	for _, label := range task.GetDependencies() {
+		dependency, exists := resolver.tasks[label]
+		if !exists {
+			return "", fmt.Errorf("task '%s' not found", label)
+		}
+		if IsCacheable(dependency) {
+			key, err := cacheKey(dependency, resolver, opts, keys)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(hash, "dependency %q %s\n", label, key)
+			continue
+		}
+		fmt.Fprintf(hash, "dependency %q\n", label)
+		outputs, err := discovery.ExpandOutputs(opts.WorkspaceDir, dependency.Outputs)
+		if err != nil {
+			return "", err
+		}
+		if err := hashFiles(hash, "output", opts.WorkspaceDir, outputs); err != nil {
+			return "", err
+		}
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	keys[task.Label] = key
+	return key, nil
+}
+
+// hashFiles writes the names and content hashes of files, given relative to
+// the workspace folder, to w.
+func hashFiles(w io.Writer, kind string, workspaceDir string, files []string) error {
+	for _, rel := range files {
+		sum, err := hashFile(filepath.Join(workspaceDir, filepath.FromSlash(rel)))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s %q %s\n", kind, rel, sum)
+	}
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Lookup returns the entry stored under key.
+func (c *Cache) Lookup(key string) (*CacheEntry, bool) {
+	entry, err := readCacheEntry(filepath.Join(c.dir, key))
+	if err != nil || entry.Key != key {
+		return nil, false
+	}
+	return entry, true
+}
+
+func readCacheEntry(dir string) (*CacheEntry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "entry.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Entries returns all stored entries, oldest first.
+func (c *Cache) Entries() ([]CacheEntry, error) {
+	dirs, err := os.ReadDir(c.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []CacheEntry
+	for _, dir := range dirs {
+		if !dir.IsDir() || strings.HasPrefix(dir.Name(), "tmp-") {
+			continue
+		}
+		path := filepath.Join(c.dir, dir.Name())
+		entry, err := readCacheEntry(path)
+		if err != nil {
+			continue
+		}
+		entry.Size = diskUsage(path)
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+	return entries, nil
+}
+
+func diskUsage(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// Clean removes the entries of the tasks with the given labels, or the whole
+// cache if no label is given. It returns the number of removed entries.
+func (c *Cache) Clean(labels ...string) (int, error) {
+	entries, err := c.Entries()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(labels) == 0 {
+		return len(entries), os.RemoveAll(c.dir)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		for _, label := range labels {
+			if entry.Label == label {
+				if err := os.RemoveAll(filepath.Join(c.dir, entry.Key)); err != nil {
+					return removed, err
+				}
+				removed++
+				break
+			}
+		}
+	}
+	return removed, nil
+}
+
+// store saves the task's output files and logged stdout under entry.Key.
+func (c *Cache) store(entry CacheEntry, log []byte, workspaceDir string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	// Entries appear atomically, so concurrent runs never see half of one
+	tmp, err := os.MkdirTemp(c.dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	for _, rel := range entry.Outputs {
+		src := filepath.Join(workspaceDir, filepath.FromSlash(rel))
+		dest := filepath.Join(tmp, "outputs", filepath.FromSlash(rel))
+		if err := copyFile(src, dest); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "stdout.log"), log, 0644); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "entry.json"), data, 0644); err != nil {
+		return err
+	}
+
+	final := filepath.Join(c.dir, entry.Key)
+	if err := os.RemoveAll(final); err != nil {
+		return err
+	}
+	return os.Rename(tmp, final)
+}
+
+// restore copies the entry's output files back into the workspace and replays
+// the logged stdout to w.
+func (c *Cache) restore(entry *CacheEntry, workspaceDir string, w io.Writer) error {
+	dir := filepath.Join(c.dir, entry.Key)
+	for _, rel := range entry.Outputs {
+		src := filepath.Join(dir, "outputs", filepath.FromSlash(rel))
+		dest := filepath.Join(workspaceDir, filepath.FromSlash(rel))
+		if err := copyFile(src, dest); err != nil {
+			return err
+		}
+	}
+
+	log, err := os.ReadFile(filepath.Join(dir, "stdout.log"))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(log)
+	return err
+}
+
+// copyFile copies a file, keeping its permissions and creating the
+// directories leading to dest.
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	// Replace instead of overwriting, the old file may be read-only
+	_ = os.Remove(dest)
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
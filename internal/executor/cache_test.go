@@ -0,0 +1,231 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestRunAll_Cache(t *testing.T) {
+	workspaceDir := t.TempDir()
+	runs := filepath.Join(t.TempDir(), "runs")
+	writeTestFile(t, filepath.Join(workspaceDir, "src", "api.proto"), "v1")
+
+	tasks := []config.Task{
+		{
+			Label:   "gen",
+			Type:    "shell",
+			Command: "echo run >> " + runs + "; mkdir -p gen; cat src/api.proto > gen/api.go; echo generated",
+			Options: &config.TaskOptions{Cwd: workspaceDir},
+			Inputs:  []string{"src/*.proto"},
+			Outputs: []string{"gen"},
+			Cache:   true,
+		},
+	}
+	cache := NewCache(t.TempDir())
+	opts := RunOptions{WorkspaceDir: workspaceDir, Cache: cache}
+	output := filepath.Join(workspaceDir, "gen", "api.go")
+
+	run := func() {
+		t.Helper()
+		if err := Run(context.Background(), &tasks[0], tasks, opts); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+	}
+
+	run()
+	if countLines(t, runs) != 1 {
+		t.Fatalf("expected the first run to execute the task")
+	}
+
+	// Same inputs: the output is restored without running the task
+	if err := os.RemoveAll(filepath.Join(workspaceDir, "gen")); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	if countLines(t, runs) != 1 {
+		t.Errorf("expected the second run to be restored from the cache")
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "v1" {
+		t.Errorf("expected restored output 'v1', got %q (%v)", data, err)
+	}
+
+	// Changed inputs run the task again
+	writeTestFile(t, filepath.Join(workspaceDir, "src", "api.proto"), "v2")
+	run()
+	if countLines(t, runs) != 2 {
+		t.Errorf("expected changed inputs to run the task again")
+	}
+
+	// Without a cache the task always runs
+	opts.Cache = nil
+	run()
+	if countLines(t, runs) != 3 {
+		t.Errorf("expected the task to run without a cache")
+	}
+
+	entries, err := cache.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 cache entries, got %d", len(entries))
+	}
+	var stdout bytes.Buffer
+	if err := cache.restore(&entries[0], workspaceDir, &stdout); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if stdout.String() != "generated\n" {
+		t.Errorf("expected logged stdout to be replayed, got %q", stdout.String())
+	}
+	if data, _ := os.ReadFile(output); string(data) != "v1" {
+		t.Errorf("expected the older entry to restore 'v1', got %q", data)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	workspaceDir := t.TempDir()
+	writeTestFile(t, filepath.Join(workspaceDir, "schema.json"), "{}")
+	writeTestFile(t, filepath.Join(workspaceDir, "main.go"), "package main")
+
+	tasks := []config.Task{
+		{Label: "schema", Type: "shell", Command: "make schema", Outputs: []string{"schema.json"}},
+		{Label: "build", Type: "shell", Command: "go build", Inputs: []string{"*.go"}, DependsOn: "schema", Cache: true},
+	}
+	opts := RunOptions{WorkspaceDir: workspaceDir}
+
+	key := func() string {
+		t.Helper()
+		k, err := CacheKey(&tasks[1], tasks, opts)
+		if err != nil {
+			t.Fatalf("CacheKey failed: %v", err)
+		}
+		return k
+	}
+
+	base := key()
+	if key() != base {
+		t.Fatal("expected the key to be stable")
+	}
+
+	changes := []struct {
+		name   string
+		change func()
+		revert func()
+	}{
+		{
+			name:   "input content",
+			change: func() { writeTestFile(t, filepath.Join(workspaceDir, "main.go"), "package main // changed") },
+			revert: func() { writeTestFile(t, filepath.Join(workspaceDir, "main.go"), "package main") },
+		},
+		{
+			name:   "dependency output",
+			change: func() { writeTestFile(t, filepath.Join(workspaceDir, "schema.json"), `{"v": 2}`) },
+			revert: func() { writeTestFile(t, filepath.Join(workspaceDir, "schema.json"), "{}") },
+		},
+		{
+			name:   "args",
+			change: func() { tasks[1].Args = []string{"-race"} },
+			revert: func() { tasks[1].Args = nil },
+		},
+		{
+			name:   "env",
+			change: func() { tasks[1].Options = &config.TaskOptions{Env: map[string]string{"CGO_ENABLED": "0"}} },
+			revert: func() { tasks[1].Options = nil },
+		},
+	}
+
+	for _, tt := range changes {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.change()
+			if key() == base {
+				t.Errorf("expected a change of %s to change the key", tt.name)
+			}
+			tt.revert()
+			if key() != base {
+				t.Errorf("expected the key to return after reverting %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestCacheKey_PluginTask(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "resolved")
+	installPlugin(t, "bazel", "touch "+marker+"\necho '{\"command\": \"bazel\"}'\n")
+
+	workspaceDir := t.TempDir()
+	task := &config.Task{}
+	if err := task.UnmarshalJSON([]byte(`{"label": "app", "type": "bazel", "target": "${workspaceFolder}/app", "x-cache": true}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := CacheKey(task, []config.Task{*task}, RunOptions{WorkspaceDir: workspaceDir})
+	if err != nil {
+		t.Fatalf("CacheKey failed: %v", err)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the cache key to be computed without asking the plugin")
+	}
+
+	other, err := CacheKey(task, []config.Task{*task}, RunOptions{WorkspaceDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key {
+		t.Error("expected substituted variables to change the key")
+	}
+}
+
+func TestCache_Clean(t *testing.T) {
+	workspaceDir := t.TempDir()
+	cache := NewCache(t.TempDir())
+
+	for _, entry := range []CacheEntry{{Key: "a1", Label: "a"}, {Key: "a2", Label: "a"}, {Key: "b1", Label: "b"}} {
+		if err := cache.store(entry, nil, workspaceDir); err != nil {
+			t.Fatalf("store failed: %v", err)
+		}
+	}
+
+	removed, err := cache.Clean("a")
+	if err != nil || removed != 2 {
+		t.Fatalf("expected 2 entries of 'a' to be removed, got %d (%v)", removed, err)
+	}
+	if _, ok := cache.Lookup("b1"); !ok {
+		t.Error("expected entries of other tasks to be kept")
+	}
+
+	removed, err = cache.Clean()
+	if err != nil || removed != 1 {
+		t.Fatalf("expected the remaining entry to be removed, got %d (%v)", removed, err)
+	}
+	if entries, _ := cache.Entries(); len(entries) != 0 {
+		t.Errorf("expected an empty cache, got %d entries", len(entries))
+	}
+}
@@ -9,14 +9,20 @@ import (
 
 type DependencyResolver struct {
 	tasks map[string]*config.Task
+	// labels keeps the order of the tasks in tasks.json
+	labels []string
 }
 
 func NewDependencyResolver(tasks []config.Task) *DependencyResolver {
 	taskMap := make(map[string]*config.Task)
+	var labels []string
 	for i := range tasks {
+		if _, exists := taskMap[tasks[i].Label]; !exists {
+			labels = append(labels, tasks[i].Label)
+		}
 		taskMap[tasks[i].Label] = &tasks[i]
 	}
-	return &DependencyResolver{tasks: taskMap}
+	return &DependencyResolver{tasks: taskMap, labels: labels}
 }
 
 func (r *DependencyResolver) ResolveExecutionOrder(taskLabel string) ([]*config.Task, error) {
@@ -169,4 +175,228 @@ func (r *DependencyResolver) GetMissingDependencies() []string {
 	
 	sort.Strings(missing)
 	return missing
-}
\ No newline at end of file
+}
+
+// Graph is the dependency graph of some tasks as an adjacency list.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+}
+
+// GraphNode is a task in a dependency graph.
+type GraphNode struct {
+	Label string `json:"label"`
+	Type  string `json:"type,omitempty"`
+	Group string `json:"group,omitempty"`
+	// Missing is set for dependencies that are not defined
+	Missing   bool        `json:"missing,omitempty"`
+	DependsOn []GraphEdge `json:"dependsOn,omitempty"`
+}
+
+// GraphEdge points from a task to one of its dependencies.
+type GraphEdge struct {
+	Label string `json:"label"`
+	// Order is "sequence" or "parallel", after the task's dependsOrder
+	Order string `json:"order"`
+	// Position is the 1-based place of a dependency in a sequence
+	Position int `json:"position,omitempty"`
+}
+
+// Graph returns the dependency graph of the task with the given label, or of
+// all tasks if label is empty. Nodes are in tasks.json order; cycles and
+// missing dependencies are kept rather than reported as errors.
+func (r *DependencyResolver) Graph(label string) (*Graph, error) {
+	roots := r.labels
+	if label != "" {
+		if _, exists := r.tasks[label]; !exists {
+			return nil, fmt.Errorf("task '%s' not found", label)
+		}
+		roots = []string{label}
+	}
+
+	included := make(map[string]bool)
+	var visit func(label string)
+	visit = func(label string) {
+		if included[label] {
+			return
+		}
+		included[label] = true
+		if task, exists := r.tasks[label]; exists {
+			for _, dep := range task.GetDependencies() {
+				visit(dep)
+			}
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	graph := &Graph{}
+	var missing []string
+	seenMissing := make(map[string]bool)
+	for _, label := range r.labels {
+		if !included[label] {
+			continue
+		}
+		task := r.tasks[label]
+		node := GraphNode{Label: label, Type: task.Type, Group: task.GetGroupKind()}
+		node.DependsOn = r.dependencyEdges(label)
+		for _, edge := range node.DependsOn {
+			if _, exists := r.tasks[edge.Label]; !exists && !seenMissing[edge.Label] {
+				seenMissing[edge.Label] = true
+				missing = append(missing, edge.Label)
+			}
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	for _, label := range missing {
+		graph.Nodes = append(graph.Nodes, GraphNode{Label: label, Missing: true})
+	}
+	return graph, nil
+}
+
+// dependencyEdges returns the edges from a task to its dependencies.
+func (r *DependencyResolver) dependencyEdges(label string) []GraphEdge {
+	task, exists := r.tasks[label]
+	if !exists {
+		return nil
+	}
+	var edges []GraphEdge
+	order := task.GetDependsOrder()
+	for i, dep := range task.GetDependencies() {
+		edge := GraphEdge{Label: dep, Order: order}
+		if order == "sequence" {
+			edge.Position = i + 1
+		}
+		edges = append(edges, edge)
+	}
+	return edges
+}
+
+// dependentEdges returns edges from a task to the tasks that depend on it
+// directly, in tasks.json order. Order and Position are those the dependent
+// gives the task.
+func (r *DependencyResolver) dependentEdges(label string) []GraphEdge {
+	var edges []GraphEdge
+	for _, dependent := range r.labels {
+		for _, edge := range r.dependencyEdges(dependent) {
+			if edge.Label == label {
+				edge.Label = dependent
+				edges = append(edges, edge)
+				break
+			}
+		}
+	}
+	return edges
+}
+
+// TreeNode is a task in a dependency or dependents tree.
+type TreeNode struct {
+	Label string
+	// Order and Position describe the edge from the parent, see GraphEdge
+	Order    string
+	Position int
+	Missing  bool
+	// Repeated is set for a task shown earlier in the tree; its children are
+	// not repeated
+	Repeated bool
+	// Cycle is set for a task that is one of its own ancestors
+	Cycle    bool
+	Children []*TreeNode
+}
+
+// DependencyTrees returns the dependsOn trees of the given tasks, or of the
+// tasks no other task depends on. Shared dependencies are expanded once; later
+// occurrences, also in later trees, are back-references marked Repeated.
+func (r *DependencyResolver) DependencyTrees(labels ...string) ([]*TreeNode, error) {
+	for _, label := range labels {
+		if _, exists := r.tasks[label]; !exists {
+			return nil, fmt.Errorf("task '%s' not found", label)
+		}
+	}
+
+	shown := make(map[string]bool)
+	var trees []*TreeNode
+	add := func(label string) {
+		trees = append(trees, r.buildTree(GraphEdge{Label: label}, r.dependencyEdges, shown, map[string]bool{}))
+	}
+
+	if len(labels) > 0 {
+		for _, label := range labels {
+			add(label)
+		}
+		return trees, nil
+	}
+
+	for _, label := range r.labels {
+		if len(r.dependentEdges(label)) == 0 {
+			add(label)
+		}
+	}
+	// Tasks that only appear in cycles have no top-level task above them
+	for _, label := range r.labels {
+		if !shown[label] {
+			add(label)
+		}
+	}
+	return trees, nil
+}
+
+// DependentTree returns the tree of the tasks that depend on the task with
+// the given label, directly or not.
+func (r *DependencyResolver) DependentTree(label string) (*TreeNode, error) {
+	if _, exists := r.tasks[label]; !exists {
+		return nil, fmt.Errorf("task '%s' not found", label)
+	}
+	return r.buildTree(GraphEdge{Label: label}, r.dependentEdges, map[string]bool{}, map[string]bool{}), nil
+}
+
+// Dependents returns the labels of the tasks that depend on the task with the
+// given label, directly or not, in tasks.json order.
+func (r *DependencyResolver) Dependents(label string) ([]string, error) {
+	tree, err := r.DependentTree(label)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool)
+	var collect func(node *TreeNode)
+	collect = func(node *TreeNode) {
+		for _, child := range node.Children {
+			found[child.Label] = true
+			collect(child)
+		}
+	}
+	collect(tree)
+
+	var dependents []string
+	for _, l := range r.labels {
+		if found[l] && l != label {
+			dependents = append(dependents, l)
+		}
+	}
+	return dependents, nil
+}
+
+func (r *DependencyResolver) buildTree(edge GraphEdge, next func(string) []GraphEdge, shown, ancestors map[string]bool) *TreeNode {
+	node := &TreeNode{Label: edge.Label, Order: edge.Order, Position: edge.Position}
+	if _, exists := r.tasks[edge.Label]; !exists {
+		node.Missing = true
+		return node
+	}
+	if ancestors[edge.Label] {
+		node.Cycle = true
+		return node
+	}
+	if shown[edge.Label] {
+		node.Repeated = true
+		return node
+	}
+	shown[edge.Label] = true
+
+	ancestors[edge.Label] = true
+	for _, child := range next(edge.Label) {
+		node.Children = append(node.Children, r.buildTree(child, next, shown, ancestors))
+	}
+	ancestors[edge.Label] = false
+	return node
+}
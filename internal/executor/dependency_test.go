@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
@@ -197,4 +199,168 @@ func TestValidateDependenciesWithCircular(t *testing.T) {
 	if err == nil {
 		t.Error("Expected validation error for circular dependency")
 	}
-}
\ No newline at end of file
+}
+
+func TestGraph(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "lint", Type: "shell"},
+		{Label: "gen", Type: "shell"},
+		{Label: "build", Type: "npm", Group: "build", DependsOn: []interface{}{"gen", "compile"}, DependsOrder: "sequence"},
+		{Label: "compile", Type: "typescript"},
+		{Label: "all", Type: "shell", DependsOn: []interface{}{"build", "lint", "docs"}},
+	}
+	resolver := NewDependencyResolver(tasks)
+
+	graph, err := resolver.Graph("build")
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	expected := &Graph{Nodes: []GraphNode{
+		{Label: "gen", Type: "shell"},
+		{Label: "build", Type: "npm", Group: "build", DependsOn: []GraphEdge{
+			{Label: "gen", Order: "sequence", Position: 1},
+			{Label: "compile", Order: "sequence", Position: 2},
+		}},
+		{Label: "compile", Type: "typescript"},
+	}}
+	if !reflect.DeepEqual(graph, expected) {
+		t.Errorf("expected %+v, got %+v", expected, graph)
+	}
+
+	graph, err = resolver.Graph("")
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	var labels []string
+	for _, node := range graph.Nodes {
+		labels = append(labels, node.Label)
+	}
+	if strings.Join(labels, ",") != "lint,gen,build,compile,all,docs" {
+		t.Errorf("expected all tasks in file order and missing ones last, got %v", labels)
+	}
+	all := graph.Nodes[4]
+	if len(all.DependsOn) != 3 || all.DependsOn[1] != (GraphEdge{Label: "lint", Order: "parallel"}) {
+		t.Errorf("expected parallel edges without positions, got %+v", all.DependsOn)
+	}
+	if !graph.Nodes[5].Missing {
+		t.Error("expected undefined dependency to be marked missing")
+	}
+
+	if _, err := resolver.Graph("nope"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
+
+func TestGraph_Cycle(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "a", DependsOn: "b"},
+		{Label: "b", DependsOn: "a"},
+	}
+	graph, err := NewDependencyResolver(tasks).Graph("a")
+	if err != nil {
+		t.Fatalf("expected cycles to be drawn, got %v", err)
+	}
+	if len(graph.Nodes) != 2 {
+		t.Errorf("expected 2 nodes, got %d", len(graph.Nodes))
+	}
+}
+
+func treeLines(nodes []*TreeNode, depth int) []string {
+	var lines []string
+	for _, node := range nodes {
+		line := strings.Repeat("  ", depth) + node.Label
+		if node.Repeated {
+			line += " ^"
+		}
+		if node.Cycle {
+			line += " @"
+		}
+		if node.Missing {
+			line += " ?"
+		}
+		lines = append(lines, line)
+		lines = append(lines, treeLines(node.Children, depth+1)...)
+	}
+	return lines
+}
+
+func TestDependencyTrees(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "codegen"},
+		{Label: "api", DependsOn: []interface{}{"codegen", "install"}, DependsOrder: "sequence"},
+		{Label: "web", DependsOn: []interface{}{"codegen", "lint"}},
+		{Label: "install"},
+		{Label: "deploy", DependsOn: []interface{}{"api", "web"}},
+		{Label: "e2e", DependsOn: "web"},
+		{Label: "a", DependsOn: "b"},
+		{Label: "b", DependsOn: "a"},
+	}
+	resolver := NewDependencyResolver(tasks)
+
+	trees, err := resolver.DependencyTrees()
+	if err != nil {
+		t.Fatalf("DependencyTrees failed: %v", err)
+	}
+	expected := []string{
+		"deploy",
+		"  api",
+		"    codegen",
+		"    install",
+		"  web",
+		"    codegen ^",
+		"    lint ?",
+		"e2e",
+		"  web ^",
+		"a",
+		"  b",
+		"    a @",
+	}
+	if got := treeLines(trees, 0); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected trees:\n%s", strings.Join(got, "\n"))
+	}
+	if api := trees[0].Children[0]; api.Order != "parallel" || api.Children[1].Order != "sequence" || api.Children[1].Position != 2 {
+		t.Errorf("expected edge orders to be kept, got %+v", api)
+	}
+
+	trees, err = resolver.DependencyTrees("web")
+	if err != nil || len(trees) != 1 || len(trees[0].Children) != 2 {
+		t.Errorf("expected a single tree for web, got %v (%v)", trees, err)
+	}
+	if _, err := resolver.DependencyTrees("nope"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
+
+func TestDependents(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "codegen"},
+		{Label: "api", DependsOn: "codegen"},
+		{Label: "web", DependsOn: "codegen"},
+		{Label: "deploy", DependsOn: []interface{}{"web", "api"}, DependsOrder: "sequence"},
+		{Label: "lint"},
+	}
+	resolver := NewDependencyResolver(tasks)
+
+	tree, err := resolver.DependentTree("codegen")
+	if err != nil {
+		t.Fatalf("DependentTree failed: %v", err)
+	}
+	expected := []string{"codegen", "  api", "    deploy", "  web", "    deploy ^"}
+	if got := treeLines([]*TreeNode{tree}, 0); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected tree:\n%s", strings.Join(got, "\n"))
+	}
+	if deploy := tree.Children[0].Children[0]; deploy.Order != "sequence" || deploy.Position != 2 {
+		t.Errorf("expected the dependent's ordering on the edge, got %+v", deploy)
+	}
+
+	dependents, err := resolver.Dependents("codegen")
+	if err != nil {
+		t.Fatalf("Dependents failed: %v", err)
+	}
+	if strings.Join(dependents, ",") != "api,web,deploy" {
+		t.Errorf("expected api, web, deploy, got %v", dependents)
+	}
+	if dependents, _ := resolver.Dependents("lint"); len(dependents) != 0 {
+		t.Errorf("expected no dependents of lint, got %v", dependents)
+	}
+}
@@ -0,0 +1,247 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType names what happened in an Event.
+type EventType string
+
+const (
+	// EventPlan lists the tasks of a run in execution order
+	EventPlan EventType = "plan"
+	// EventTaskStarted carries the resolved command line of a started task
+	EventTaskStarted EventType = "taskStarted"
+	// EventTaskReady is sent when a background task reports readiness
+	EventTaskReady EventType = "taskReady"
+	// EventOutput is one line a task wrote to stdout or stderr
+	EventOutput EventType = "output"
+	// EventDiagnostic is a problem found by a problem matcher
+	EventDiagnostic EventType = "diagnostic"
+	// EventTaskSkipped is sent for tasks that are up to date or restored from
+	// the cache
+	EventTaskSkipped EventType = "taskSkipped"
+	// EventTaskFinished carries the exit code and duration of a task
+	EventTaskFinished EventType = "taskFinished"
+	// EventRunFinished ends a run
+	EventRunFinished EventType = "runFinished"
+)
+
+// Event is something that happened while running tasks. Only the fields that
+// belong to the event's type are set.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	Task string    `json:"task,omitempty"`
+
+	// EventPlan
+	Tasks []string `json:"tasks,omitempty"`
+
+	// EventTaskStarted
+	Command      string   `json:"command,omitempty"`
+	Args         []string `json:"args,omitempty"`
+	Cwd          string   `json:"cwd,omitempty"`
+	EnvKeys      []string `json:"envKeys,omitempty"`
+	PID          int      `json:"pid,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Background   bool     `json:"background,omitempty"`
+
+	// EventOutput
+	Stream string `json:"stream,omitempty"`
+	Line   string `json:"line,omitempty"`
+
+	// EventDiagnostic
+	Diagnostic *Diagnostic `json:"diagnostic,omitempty"`
+
+	// EventTaskSkipped: "up to date" or "cached"
+	Status string `json:"status,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// EventTaskFinished and EventRunFinished
+	ExitCode *int          `json:"exitCode,omitempty"`
+	Duration time.Duration `json:"-"`
+	Error    string        `json:"error,omitempty"`
+	// Stopped is set for tasks stopped by the run, like background
+	// dependencies at its end, rather than exiting by themselves
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// MarshalJSON writes the duration in milliseconds.
+func (e Event) MarshalJSON() ([]byte, error) {
+	type plainEvent Event
+	var durationMs *float64
+	if e.Type == EventTaskFinished || e.Type == EventRunFinished {
+		ms := float64(e.Duration) / float64(time.Millisecond)
+		durationMs = &ms
+	}
+	return json.Marshal(struct {
+		plainEvent
+		DurationMs *float64 `json:"durationMs,omitempty"`
+	}{plainEvent(e), durationMs})
+}
+
+// EventBus delivers the events of runs to its subscribers, one event at a
+// time and in the order they were published.
+type EventBus struct {
+	mu            sync.Mutex
+	nextID        int
+	subscriptions map[int]subscription
+	order         []int
+}
+
+type subscription struct {
+	handler func(Event)
+	// types are the event types delivered to handler, or nil for all
+	types map[EventType]bool
+}
+
+func (s subscription) wants(eventType EventType) bool {
+	return s.types == nil || s.types[eventType]
+}
+
+// NewEventBus returns a bus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{subscriptions: make(map[int]subscription)}
+}
+
+// Subscribe calls handler for every event published from now on, until the
+// returned function is called. If types are given, only events of these
+// types are delivered. Handlers must not publish events themselves.
+//
+// Task output is only split into EventOutput lines while a subscriber wants
+// them; otherwise tasks write to the terminal directly.
+func (b *EventBus) Subscribe(handler func(Event), types ...EventType) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := subscription{handler: handler}
+	if len(types) > 0 {
+		s.types = make(map[EventType]bool, len(types))
+		for _, eventType := range types {
+			s.types[eventType] = true
+		}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subscriptions[id] = s
+	b.order = append(b.order, id)
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscriptions, id)
+		for i, other := range b.order {
+			if other == id {
+				b.order = append(b.order[:i], b.order[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func (b *EventBus) publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, id := range b.order {
+		if s := b.subscriptions[id]; s.wants(event.Type) {
+			s.handler(event)
+		}
+	}
+}
+
+// wants reports whether a subscriber wants events of the given type.
+func (b *EventBus) wants(eventType EventType) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subscriptions {
+		if s.wants(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSONLines returns an event handler that writes each event to w as a
+// line of JSON.
+func WriteJSONLines(w io.Writer) func(Event) {
+	encoder := json.NewEncoder(w)
+	return func(event Event) {
+		_ = encoder.Encode(event)
+	}
+}
+
+// withEvents makes sure opts has an event bus and prints the events of the
+// run to the terminal until the returned function is called.
+func withEvents(opts RunOptions) (RunOptions, func()) {
+	if opts.Events == nil {
+		opts.Events = NewEventBus()
+	}
+	if opts.Silent {
+		return opts, func() {}
+	}
+	console := &consoleOutput{diagnostics: make(map[string][]Diagnostic)}
+	return opts, opts.Events.Subscribe(console.handle, EventDiagnostic, EventTaskSkipped, EventTaskFinished)
+}
+
+// consoleOutput is the human-readable view of a run: problems are summarized
+// when a task finishes.
+//
+// Task output is the one part of the view that does not come from the bus.
+// Tasks write to stdout and stderr as the output arrives, so that prompts and
+// progress bars show immediately, and the same bytes are split into the
+// EventOutput lines of the bus.
+type consoleOutput struct {
+	diagnostics map[string][]Diagnostic
+}
+
+func (c *consoleOutput) handle(event Event) {
+	switch event.Type {
+	case EventDiagnostic:
+		c.diagnostics[event.Task] = append(c.diagnostics[event.Task], *event.Diagnostic)
+	case EventTaskSkipped:
+		if event.Status == "cached" {
+			fmt.Fprintf(os.Stderr, "Task '%s' restored from cache\n", event.Task)
+		} else {
+			fmt.Fprintf(os.Stderr, "Task '%s' is %s\n", event.Task, event.Status)
+		}
+	case EventTaskFinished:
+		printDiagnostics(event.Task, c.diagnostics[event.Task])
+		delete(c.diagnostics, event.Task)
+	}
+}
+
+// printDiagnostics prints the problems found in the output of a task.
+func printDiagnostics(label string, diagnostics []Diagnostic) {
+	if len(diagnostics) == 0 {
+		return
+	}
+
+	errors, warnings := 0, 0
+	for _, d := range diagnostics {
+		if d.Severity == "error" {
+			errors++
+		} else if d.Severity == "warning" {
+			warnings++
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Problems in task '%s': %d errors, %d warnings\n", label, errors, warnings)
+	for _, d := range diagnostics {
+		fmt.Fprintf(os.Stderr, "  %s\n", d)
+	}
+}
@@ -0,0 +1,240 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestRun_Events(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "gen", Type: "shell", Command: "echo generated; echo oops >&2", Options: &config.TaskOptions{Env: map[string]string{"B": "2", "A": "1"}}},
+		{Label: "vet", Type: "shell", Command: "echo 'main.go:12:3: unreachable code'; exit 1", ProblemMatcher: "$go", DependsOn: "gen"},
+	}
+	events := NewEventBus()
+	var received []Event
+	events.Subscribe(func(event Event) {
+		received = append(received, event)
+	})
+
+	err := Run(context.Background(), &tasks[1], tasks, RunOptions{WorkspaceDir: t.TempDir(), Events: events})
+	if err == nil {
+		t.Fatal("expected the failing task to fail the run")
+	}
+
+	var types []string
+	for _, event := range received {
+		types = append(types, string(event.Type))
+	}
+	// Output lines of one task come from two pipes, so only their own order is fixed
+	expected := []string{"plan", "taskStarted", "output", "output", "taskFinished", "taskStarted", "output", "diagnostic", "taskFinished", "runFinished"}
+	if strings.Join(types, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected events %v, got %v", expected, types)
+	}
+
+	if strings.Join(received[0].Tasks, ",") != "gen,vet" {
+		t.Errorf("expected plan gen,vet, got %v", received[0].Tasks)
+	}
+	started := received[1]
+	if started.Task != "gen" || started.PID == 0 || strings.Join(started.EnvKeys, ",") != "A,B" {
+		t.Errorf("unexpected taskStarted event %+v", started)
+	}
+	lines := make(map[string]string)
+	for _, event := range received[2:4] {
+		lines[event.Stream] = event.Line
+	}
+	if lines["stdout"] != "generated" || lines["stderr"] != "oops" {
+		t.Errorf("expected one line per stream, got %v", lines)
+	}
+	if d := received[7].Diagnostic; d == nil || d.Line != 12 || d.Message != "unreachable code" {
+		t.Errorf("unexpected diagnostic %+v", d)
+	}
+	if finished := received[8]; finished.ExitCode == nil || *finished.ExitCode != 1 {
+		t.Errorf("expected exit code 1, got %+v", finished)
+	}
+	if run := received[9]; run.Status != "failed" || run.Error == "" {
+		t.Errorf("expected a failed run, got %+v", run)
+	}
+}
+
+func TestRun_Silent(t *testing.T) {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+	task := &config.Task{Label: "vet", Type: "shell", Command: "cat; echo 'main.go:12:3: unreachable code'", ProblemMatcher: "$go"}
+	events := NewEventBus()
+	var lines []string
+	events.Subscribe(func(event Event) {
+		if event.Type == EventOutput {
+			lines = append(lines, event.Line)
+		}
+	})
+	err := Run(context.Background(), task, []config.Task{*task}, RunOptions{WorkspaceDir: t.TempDir(), Events: events, Silent: true})
+
+	_ = w.Close()
+	printed, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) != 0 {
+		t.Errorf("expected a silent run to print nothing, got %q", printed)
+	}
+	if len(lines) != 1 {
+		t.Errorf("expected the output to reach subscribers, got %v", lines)
+	}
+}
+
+func TestRun_PromptsShowImmediately(t *testing.T) {
+	oldStdin, oldStdout := os.Stdin, os.Stdout
+	stdinRead, stdinWrite, _ := os.Pipe()
+	stdoutRead, stdoutWrite, _ := os.Pipe()
+	os.Stdin, os.Stdout = stdinRead, stdoutWrite
+	defer func() { os.Stdin, os.Stdout = oldStdin, oldStdout }()
+
+	task := &config.Task{Label: "ask", Type: "shell", Command: "printf 'Continue? '; read answer; echo \"got $answer\""}
+	events := NewEventBus()
+	var lines []string
+	events.Subscribe(func(event Event) {
+		lines = append(lines, event.Line)
+	}, EventOutput)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(context.Background(), task, []config.Task{*task}, RunOptions{WorkspaceDir: t.TempDir(), Events: events})
+	}()
+
+	prompt := make([]byte, len("Continue? "))
+	if _, err := io.ReadFull(stdoutRead, prompt); err != nil || string(prompt) != "Continue? " {
+		t.Fatalf("expected the prompt before the answer, got %q, %v", prompt, err)
+	}
+	_, _ = stdinWrite.WriteString("yes\n")
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	_ = stdoutWrite.Close()
+	rest, _ := io.ReadAll(stdoutRead)
+	if string(rest) != "got yes\n" {
+		t.Errorf("expected the rest of the output, got %q", rest)
+	}
+	if len(lines) != 1 || lines[0] != "Continue? got yes" {
+		t.Errorf("expected the output lines to reach subscribers, got %q", lines)
+	}
+}
+
+func TestRun_ConsoleMatchesEventStream(t *testing.T) {
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	stdoutRead, stdoutWrite, _ := os.Pipe()
+	stderrRead, stderrWrite, _ := os.Pipe()
+	os.Stdout, os.Stderr = stdoutWrite, stderrWrite
+	defer func() { os.Stdout, os.Stderr = oldStdout, oldStderr }()
+
+	task := &config.Task{Label: "gen", Type: "shell", Command: "echo one; echo two >&2; printf 'three\\nfour'"}
+	events := NewEventBus()
+	var stream bytes.Buffer
+	events.Subscribe(WriteJSONLines(&stream))
+	err := Run(context.Background(), task, []config.Task{*task}, RunOptions{WorkspaceDir: t.TempDir(), Events: events})
+
+	_ = stdoutWrite.Close()
+	_ = stderrWrite.Close()
+	printedStdout, _ := io.ReadAll(stdoutRead)
+	printedStderr, _ := io.ReadAll(stderrRead)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	streamed := map[string][]string{}
+	for _, line := range strings.Split(strings.TrimSpace(stream.String()), "\n") {
+		var event Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Type == EventOutput {
+			streamed[event.Stream] = append(streamed[event.Stream], event.Line)
+		}
+	}
+	if got := strings.Join(streamed["stdout"], "\n"); got != string(printedStdout) {
+		t.Errorf("expected the stdout events to match the console, got %q and %q", got, printedStdout)
+	}
+	if got := strings.Join(streamed["stderr"], "\n") + "\n"; got != string(printedStderr) {
+		t.Errorf("expected the stderr events to match the console, got %q and %q", got, printedStderr)
+	}
+}
+
+func TestStartTask_KeepsTerminal(t *testing.T) {
+	task := &config.Task{Label: "build", Type: "shell", Command: "true"}
+	events := NewEventBus()
+	events.Subscribe(func(Event) {}, EventTaskFinished)
+
+	running, err := startTask(context.Background(), task, RunOptions{WorkspaceDir: t.TempDir(), Events: events})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := running.wait(); err != nil {
+		t.Fatal(err)
+	}
+	if running.cmd.Stdout != os.Stdout || running.cmd.Stderr != os.Stderr {
+		t.Error("expected the task to write to the terminal when nobody reads its lines")
+	}
+}
+
+func TestEventBus_SubscribeTypes(t *testing.T) {
+	events := NewEventBus()
+	var received []EventType
+	unsubscribe := events.Subscribe(func(event Event) {
+		received = append(received, event.Type)
+	}, EventTaskFinished)
+
+	events.publish(Event{Type: EventOutput})
+	events.publish(Event{Type: EventTaskFinished})
+	if len(received) != 1 || received[0] != EventTaskFinished {
+		t.Errorf("expected only taskFinished events, got %v", received)
+	}
+	if events.wants(EventOutput) || !events.wants(EventTaskFinished) {
+		t.Error("expected the bus to want only taskFinished events")
+	}
+	unsubscribe()
+	if events.wants(EventTaskFinished) {
+		t.Error("expected no wanted events after unsubscribing")
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	var out bytes.Buffer
+	exitCode := 0
+	handler := WriteJSONLines(&out)
+	handler(Event{Type: EventOutput, Task: "build", Stream: "stdout", Line: "ok"})
+	handler(Event{Type: EventTaskFinished, Task: "build", ExitCode: &exitCode, Duration: 1500 * time.Millisecond})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out.String())
+	}
+
+	var output map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &output); err != nil {
+		t.Fatal(err)
+	}
+	if output["line"] != "ok" || output["stream"] != "stdout" {
+		t.Errorf("unexpected output event %v", output)
+	}
+	if _, ok := output["durationMs"]; ok {
+		t.Error("expected no duration on output events")
+	}
+
+	var finished map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &finished); err != nil {
+		t.Fatal(err)
+	}
+	if finished["durationMs"] != 1500.0 || finished["exitCode"] != 0.0 {
+		t.Errorf("unexpected taskFinished event %v", finished)
+	}
+}
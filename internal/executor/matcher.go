@@ -0,0 +1,519 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic is a problem reported by a task's output, such as a compile error.
+type Diagnostic struct {
+	Owner     string `json:"owner,omitempty"`
+	Source    string `json:"source,omitempty"`
+	File      string `json:"file"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Severity  string `json:"severity"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location += ":" + strconv.Itoa(d.Line)
+		if d.Column > 0 {
+			location += ":" + strconv.Itoa(d.Column)
+		}
+	}
+	if d.Code != "" {
+		return fmt.Sprintf("%s: %s %s: %s", location, d.Severity, d.Code, d.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", location, d.Severity, d.Message)
+}
+
+// ProblemPattern describes one line of a problem matcher. The integer fields
+// are regexp group indexes, zero meaning "not captured".
+type ProblemPattern struct {
+	Regexp    *regexp.Regexp
+	File      int
+	Location  int
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Severity  int
+	Code      int
+	Message   int
+	Loop      bool
+}
+
+// BackgroundMatcher detects the begin and end of a build cycle in the output
+// of a background task. The end pattern marks the task as ready.
+type BackgroundMatcher struct {
+	ActiveOnStart bool
+	BeginsPattern *regexp.Regexp
+	EndsPattern   *regexp.Regexp
+}
+
+// ProblemMatcher turns task output into diagnostics, like VS Code's problemMatcher.
+type ProblemMatcher struct {
+	Name         string
+	Owner        string
+	Source       string
+	Severity     string
+	FileLocation string
+	FileBase     string
+	Patterns     []ProblemPattern
+	Background   *BackgroundMatcher
+}
+
+var tscPattern = ProblemPattern{
+	Regexp:   regexp.MustCompile(`^([^\s].*)[\(:](\d+)[,:](\d+)(?:\):\s+|\s+-\s+)(error|warning|info)\s+(TS\d+)\s*:\s*(.*)$`),
+	File:     1,
+	Line:     2,
+	Column:   3,
+	Severity: 4,
+	Code:     5,
+	Message:  6,
+}
+
+var builtinProblemMatchers = map[string]func() *ProblemMatcher{
+	"$tsc": func() *ProblemMatcher {
+		return &ProblemMatcher{
+			Name:         "$tsc",
+			Owner:        "typescript",
+			Source:       "ts",
+			FileLocation: "relative",
+			Patterns:     []ProblemPattern{tscPattern},
+		}
+	},
+	"$tsc-watch": func() *ProblemMatcher {
+		return &ProblemMatcher{
+			Name:         "$tsc-watch",
+			Owner:        "typescript",
+			Source:       "ts",
+			FileLocation: "relative",
+			Patterns:     []ProblemPattern{tscPattern},
+			Background: &BackgroundMatcher{
+				ActiveOnStart: true,
+				BeginsPattern: regexp.MustCompile(`(?:Starting compilation in watch mode|File change detected\. Starting incremental compilation)\.\.\.`),
+				EndsPattern:   regexp.MustCompile(`(?:Compilation complete\.|Found \d+ errors?\.) Watching for file changes\.`),
+			},
+		}
+	},
+	"$go": func() *ProblemMatcher {
+		return &ProblemMatcher{
+			Name:         "$go",
+			Owner:        "go",
+			Source:       "go",
+			Severity:     "error",
+			FileLocation: "relative",
+			Patterns: []ProblemPattern{{
+				Regexp:  regexp.MustCompile(`^\s*([^:\s][^:]*\.go):(\d+):(?:(\d+):)?\s*(.*)$`),
+				File:    1,
+				Line:    2,
+				Column:  3,
+				Message: 4,
+			}},
+		}
+	},
+	"$gcc": func() *ProblemMatcher {
+		return &ProblemMatcher{
+			Name:         "$gcc",
+			Owner:        "cpp",
+			Source:       "gcc",
+			FileLocation: "autoDetect",
+			Patterns: []ProblemPattern{{
+				Regexp:   regexp.MustCompile(`^(.*?):(\d+):(\d*):?\s+(?:fatal\s+)?(warning|error):\s+(.*)$`),
+				File:     1,
+				Line:     2,
+				Column:   3,
+				Severity: 4,
+				Message:  5,
+			}},
+		}
+	},
+}
+
+// UnsupportedProblemMatcherError reports a problem matcher that may well work
+// in VS Code but not here, like one based on a matcher contributed by an
+// extension or one using regexp syntax that Go does not support.
+type UnsupportedProblemMatcherError struct {
+	Reason string
+}
+
+func (e *UnsupportedProblemMatcherError) Error() string {
+	return e.Reason
+}
+
+// ParseProblemMatchers converts a task's "problemMatcher" property (a name,
+// an object or an array of both) into problem matchers. Unknown matcher names
+// are skipped. Invalid or unsupported matcher objects are dropped: the other
+// matchers are returned together with an error describing the dropped ones.
+func ParseProblemMatchers(value interface{}) ([]*ProblemMatcher, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		// Matchers contributed by VS Code extensions are unknown here and skipped
+		if !IsKnownProblemMatcher(v) {
+			return nil, nil
+		}
+		matcher, _ := namedProblemMatcher(v)
+		return []*ProblemMatcher{matcher}, nil
+	case map[string]interface{}:
+		matcher, err := parseProblemMatcherObject(v)
+		if err != nil {
+			return nil, err
+		}
+		return []*ProblemMatcher{matcher}, nil
+	case []interface{}:
+		var matchers []*ProblemMatcher
+		var errs []error
+		for _, item := range v {
+			parsed, err := ParseProblemMatchers(item)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			matchers = append(matchers, parsed...)
+		}
+		return matchers, errors.Join(errs...)
+	case []string:
+		var matchers []*ProblemMatcher
+		for _, name := range v {
+			if IsKnownProblemMatcher(name) {
+				matcher, _ := namedProblemMatcher(name)
+				matchers = append(matchers, matcher)
+			}
+		}
+		return matchers, nil
+	}
+
+	return nil, fmt.Errorf("invalid problemMatcher: %v", value)
+}
+
+// IsKnownProblemMatcher reports whether name refers to a built-in problem matcher.
+func IsKnownProblemMatcher(name string) bool {
+	_, ok := builtinProblemMatchers[name]
+	return ok
+}
+
+func namedProblemMatcher(name string) (*ProblemMatcher, error) {
+	factory, ok := builtinProblemMatchers[name]
+	if !ok {
+		return nil, &UnsupportedProblemMatcherError{Reason: fmt.Sprintf("unknown problem matcher: %s", name)}
+	}
+	return factory(), nil
+}
+
+func parseProblemMatcherObject(obj map[string]interface{}) (*ProblemMatcher, error) {
+	matcher := &ProblemMatcher{FileLocation: "relative"}
+
+	if base, ok := obj["base"].(string); ok {
+		baseMatcher, err := namedProblemMatcher(base)
+		if err != nil {
+			return nil, err
+		}
+		matcher = baseMatcher
+	}
+
+	if owner, ok := obj["owner"].(string); ok {
+		matcher.Owner = owner
+	}
+	if source, ok := obj["source"].(string); ok {
+		matcher.Source = source
+	}
+	if severity, ok := obj["severity"].(string); ok {
+		matcher.Severity = severity
+	}
+
+	switch location := obj["fileLocation"].(type) {
+	case string:
+		matcher.FileLocation = location
+	case []interface{}:
+		if len(location) > 0 {
+			matcher.FileLocation, _ = location[0].(string)
+		}
+		if len(location) > 1 {
+			matcher.FileBase, _ = location[1].(string)
+		}
+	}
+
+	if pattern, ok := obj["pattern"]; ok {
+		patterns, err := parseProblemPatterns(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matcher.Patterns = patterns
+	}
+	if len(matcher.Patterns) == 0 {
+		return nil, fmt.Errorf("problem matcher requires a 'pattern'")
+	}
+
+	background, ok := obj["background"].(map[string]interface{})
+	if !ok {
+		background, ok = obj["watching"].(map[string]interface{})
+	}
+	if ok {
+		parsed, err := parseBackgroundMatcher(background)
+		if err != nil {
+			return nil, err
+		}
+		matcher.Background = parsed
+	}
+
+	return matcher, nil
+}
+
+func parseProblemPatterns(value interface{}) ([]ProblemPattern, error) {
+	switch v := value.(type) {
+	case string:
+		matcher, err := namedProblemMatcher(v)
+		if err != nil {
+			return nil, err
+		}
+		return matcher.Patterns, nil
+	case map[string]interface{}:
+		pattern, err := parseProblemPattern(v, true)
+		if err != nil {
+			return nil, err
+		}
+		return []ProblemPattern{pattern}, nil
+	case []interface{}:
+		var patterns []ProblemPattern
+		for _, item := range v {
+			obj, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid problem pattern: %v", item)
+			}
+			pattern, err := parseProblemPattern(obj, false)
+			if err != nil {
+				return nil, err
+			}
+			patterns = append(patterns, pattern)
+		}
+		return patterns, nil
+	}
+
+	return nil, fmt.Errorf("invalid problem pattern: %v", value)
+}
+
+// parseProblemPattern parses a pattern object. Single-line patterns default to
+// VS Code's group layout (file=1, line=2, column=3, severity=4, message=5).
+func parseProblemPattern(obj map[string]interface{}, singleLine bool) (ProblemPattern, error) {
+	var pattern ProblemPattern
+
+	expr, ok := obj["regexp"].(string)
+	if !ok {
+		return pattern, fmt.Errorf("problem pattern requires a 'regexp'")
+	}
+	re, err := compileMatcherRegexp(expr)
+	if err != nil {
+		return pattern, err
+	}
+	pattern.Regexp = re
+
+	if singleLine {
+		pattern.File, pattern.Line, pattern.Column, pattern.Severity, pattern.Message = 1, 2, 3, 4, 5
+	}
+
+	groups := map[string]*int{
+		"file":      &pattern.File,
+		"location":  &pattern.Location,
+		"line":      &pattern.Line,
+		"column":    &pattern.Column,
+		"endLine":   &pattern.EndLine,
+		"endColumn": &pattern.EndColumn,
+		"severity":  &pattern.Severity,
+		"code":      &pattern.Code,
+		"message":   &pattern.Message,
+	}
+	for key, target := range groups {
+		if index, ok := obj[key].(float64); ok {
+			*target = int(index)
+		}
+	}
+	if loop, ok := obj["loop"].(bool); ok {
+		pattern.Loop = loop
+	}
+
+	return pattern, nil
+}
+
+func parseBackgroundMatcher(obj map[string]interface{}) (*BackgroundMatcher, error) {
+	background := &BackgroundMatcher{}
+	if active, ok := obj["activeOnStart"].(bool); ok {
+		background.ActiveOnStart = active
+	}
+
+	compile := func(value interface{}) (*regexp.Regexp, error) {
+		switch v := value.(type) {
+		case nil:
+			return nil, nil
+		case string:
+			return compileMatcherRegexp(v)
+		case map[string]interface{}:
+			if expr, ok := v["regexp"].(string); ok {
+				return compileMatcherRegexp(expr)
+			}
+		}
+		return nil, fmt.Errorf("invalid background pattern: %v", value)
+	}
+
+	var err error
+	if background.BeginsPattern, err = compile(obj["beginsPattern"]); err != nil {
+		return nil, err
+	}
+	if background.EndsPattern, err = compile(obj["endsPattern"]); err != nil {
+		return nil, err
+	}
+	return background, nil
+}
+
+// compileMatcherRegexp compiles a regexp of a problem matcher. VS Code uses
+// JavaScript regexps, so lookarounds, backreferences and the like are
+// reported as unsupported rather than invalid.
+func compileMatcherRegexp(expr string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(expr)
+	if err == nil {
+		return re, nil
+	}
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		switch syntaxErr.Code {
+		case syntax.ErrInvalidPerlOp, syntax.ErrInvalidEscape, syntax.ErrInvalidNamedCapture, syntax.ErrInvalidRepeatSize:
+			return nil, &UnsupportedProblemMatcherError{Reason: fmt.Sprintf("unsupported problem pattern regexp: %v", err)}
+		}
+	}
+	return nil, fmt.Errorf("invalid problem pattern regexp: %w", err)
+}
+
+// matcherState tracks the progress of one problem matcher over the output.
+type matcherState struct {
+	matcher      *ProblemMatcher
+	cwd          string
+	workspaceDir string
+	index        int
+	partial      Diagnostic
+}
+
+// match feeds a line to the matcher and returns a diagnostic when a pattern
+// sequence completes.
+func (s *matcherState) match(line string) (Diagnostic, bool) {
+	patterns := s.matcher.Patterns
+
+	for {
+		pattern := patterns[s.index]
+		groups := pattern.Regexp.FindStringSubmatch(line)
+		if groups == nil {
+			if s.index > 0 {
+				// A looping last pattern ends, or the sequence broke; restart
+				s.index = 0
+				s.partial = Diagnostic{}
+				continue
+			}
+			return Diagnostic{}, false
+		}
+
+		s.fill(pattern, groups)
+		if s.index < len(patterns)-1 {
+			s.index++
+			return Diagnostic{}, false
+		}
+
+		diagnostic := s.finish()
+		if !pattern.Loop {
+			s.index = 0
+			s.partial = Diagnostic{}
+		} else {
+			// Keep the fields of the previous lines for the next loop iteration
+			s.partial.Message, s.partial.Code, s.partial.Severity = "", "", ""
+			s.partial.Line, s.partial.Column, s.partial.EndLine, s.partial.EndColumn = 0, 0, 0, 0
+		}
+		return diagnostic, true
+	}
+}
+
+func (s *matcherState) fill(pattern ProblemPattern, groups []string) {
+	get := func(index int) string {
+		if index <= 0 || index >= len(groups) {
+			return ""
+		}
+		return groups[index]
+	}
+	getInt := func(index int) int {
+		n, _ := strconv.Atoi(get(index))
+		return n
+	}
+
+	if v := get(pattern.File); v != "" {
+		s.partial.File = v
+	}
+	if v := get(pattern.Location); v != "" {
+		parts := strings.Split(v, ",")
+		s.partial.Line, _ = strconv.Atoi(parts[0])
+		if len(parts) > 1 {
+			s.partial.Column, _ = strconv.Atoi(parts[1])
+		}
+	}
+	if v := getInt(pattern.Line); v > 0 {
+		s.partial.Line = v
+	}
+	if v := getInt(pattern.Column); v > 0 {
+		s.partial.Column = v
+	}
+	if v := getInt(pattern.EndLine); v > 0 {
+		s.partial.EndLine = v
+	}
+	if v := getInt(pattern.EndColumn); v > 0 {
+		s.partial.EndColumn = v
+	}
+	if v := get(pattern.Severity); v != "" {
+		s.partial.Severity = strings.ToLower(v)
+	}
+	if v := get(pattern.Code); v != "" {
+		s.partial.Code = v
+	}
+	if v := get(pattern.Message); v != "" {
+		s.partial.Message = v
+	}
+}
+
+func (s *matcherState) finish() Diagnostic {
+	diagnostic := s.partial
+	diagnostic.Owner = s.matcher.Owner
+	diagnostic.Source = s.matcher.Source
+	if diagnostic.Severity == "" {
+		diagnostic.Severity = s.matcher.Severity
+	}
+	if diagnostic.Severity == "" {
+		diagnostic.Severity = "error"
+	}
+	diagnostic.File = s.resolveFile(diagnostic.File)
+	return diagnostic
+}
+
+func (s *matcherState) resolveFile(file string) string {
+	if file == "" || filepath.IsAbs(file) {
+		return file
+	}
+
+	switch s.matcher.FileLocation {
+	case "absolute":
+		return file
+	case "relative", "autoDetect", "":
+		base := s.cwd
+		if s.matcher.FileBase != "" {
+			base = strings.ReplaceAll(s.matcher.FileBase, "${workspaceFolder}", s.workspaceDir)
+		}
+		return filepath.Join(base, file)
+	}
+	return file
+}
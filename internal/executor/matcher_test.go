@@ -0,0 +1,183 @@
+package executor
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseProblemMatchers_Tsc(t *testing.T) {
+	matchers, err := ParseProblemMatchers("$tsc")
+	if err != nil {
+		t.Fatalf("ParseProblemMatchers failed: %v", err)
+	}
+	if len(matchers) != 1 {
+		t.Fatalf("expected 1 matcher, got %d", len(matchers))
+	}
+
+	state := &matcherState{matcher: matchers[0], cwd: "/project"}
+
+	diagnostic, ok := state.match("src/index.ts(3,7): error TS2322: Type 'string' is not assignable to type 'number'.")
+	if !ok {
+		t.Fatal("expected classic tsc output to match")
+	}
+	expected := Diagnostic{
+		Owner:    "typescript",
+		Source:   "ts",
+		File:     filepath.Join("/project", "src/index.ts"),
+		Line:     3,
+		Column:   7,
+		Severity: "error",
+		Code:     "TS2322",
+		Message:  "Type 'string' is not assignable to type 'number'.",
+	}
+	if diagnostic != expected {
+		t.Errorf("unexpected diagnostic:\n got %+v\nwant %+v", diagnostic, expected)
+	}
+
+	diagnostic, ok = state.match("src/util.ts:10:1 - warning TS6133: 'x' is declared but its value is never read.")
+	if !ok {
+		t.Fatal("expected pretty tsc output to match")
+	}
+	if diagnostic.Line != 10 || diagnostic.Column != 1 || diagnostic.Severity != "warning" {
+		t.Errorf("unexpected diagnostic: %+v", diagnostic)
+	}
+
+	if _, ok := state.match("Found 2 errors."); ok {
+		t.Error("expected summary line not to match")
+	}
+}
+
+func TestParseProblemMatchers_UnknownNamesSkipped(t *testing.T) {
+	matchers, err := ParseProblemMatchers([]interface{}{"$eslint-stylish", "$go"})
+	if err != nil {
+		t.Fatalf("ParseProblemMatchers failed: %v", err)
+	}
+	if len(matchers) != 1 || matchers[0].Name != "$go" {
+		t.Errorf("expected only $go matcher, got %v", matchers)
+	}
+}
+
+func TestParseProblemMatchers_CustomMultiLine(t *testing.T) {
+	definition := map[string]interface{}{
+		"owner":        "lint",
+		"fileLocation": []interface{}{"relative", "${workspaceFolder}"},
+		"pattern": []interface{}{
+			map[string]interface{}{"regexp": `^([^\s].*)$`, "file": float64(1)},
+			map[string]interface{}{
+				"regexp":   `^\s+(\d+):(\d+)\s+(error|warning)\s+(.*)\s\s+(.*)$`,
+				"line":     float64(1),
+				"column":   float64(2),
+				"severity": float64(3),
+				"message":  float64(4),
+				"code":     float64(5),
+				"loop":     true,
+			},
+		},
+	}
+
+	matchers, err := ParseProblemMatchers(definition)
+	if err != nil {
+		t.Fatalf("ParseProblemMatchers failed: %v", err)
+	}
+
+	state := &matcherState{matcher: matchers[0], cwd: "/project/web", workspaceDir: "/project"}
+	var diagnostics []Diagnostic
+	for _, line := range []string{
+		"src/app.js",
+		"  1:10  error  'foo' is defined but never used  no-unused-vars",
+		"  4:1   warning  Unexpected console statement  no-console",
+		"",
+		"src/other.js",
+		"  2:3  error  Missing semicolon  semi",
+	} {
+		if d, ok := state.match(line); ok {
+			diagnostics = append(diagnostics, d)
+		}
+	}
+
+	if len(diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %d: %+v", len(diagnostics), diagnostics)
+	}
+	if diagnostics[1].File != filepath.Join("/project", "src/app.js") || diagnostics[1].Severity != "warning" || diagnostics[1].Code != "no-console" {
+		t.Errorf("unexpected looped diagnostic: %+v", diagnostics[1])
+	}
+	if diagnostics[2].File != filepath.Join("/project", "src/other.js") || diagnostics[2].Line != 2 {
+		t.Errorf("unexpected diagnostic for second file: %+v", diagnostics[2])
+	}
+}
+
+func TestParseProblemMatchers_Invalid(t *testing.T) {
+	tests := []interface{}{
+		map[string]interface{}{"owner": "x"},
+		map[string]interface{}{"pattern": map[string]interface{}{"regexp": "("}},
+		42.0,
+	}
+	for _, definition := range tests {
+		_, err := ParseProblemMatchers(definition)
+		var unsupported *UnsupportedProblemMatcherError
+		if err == nil || errors.As(err, &unsupported) {
+			t.Errorf("expected invalid matcher error for %v, got %v", definition, err)
+		}
+	}
+}
+
+func TestParseProblemMatchers_Unsupported(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"unknown base":          {"base": "$eslint-stylish"},
+		"unknown named pattern": {"pattern": "$eslint-compact"},
+		"lookahead regexp":      {"pattern": map[string]interface{}{"regexp": `^(.*):(\d+)(?=:)`}},
+		"backreference":         {"pattern": map[string]interface{}{"regexp": `^(['"])(.*)\1`}},
+	}
+	for name, definition := range tests {
+		t.Run(name, func(t *testing.T) {
+			matchers, err := ParseProblemMatchers([]interface{}{definition, "$go"})
+			var unsupported *UnsupportedProblemMatcherError
+			if !errors.As(err, &unsupported) {
+				t.Errorf("expected unsupported matcher error, got %v", err)
+			}
+			if len(matchers) != 1 || matchers[0].Name != "$go" {
+				t.Errorf("expected the other matchers to be kept, got %v", matchers)
+			}
+		})
+	}
+}
+
+func TestOutputScanner_Background(t *testing.T) {
+	matchers, err := ParseProblemMatchers("$tsc-watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := newOutputScanner(matchers, "/project", "/project")
+
+	select {
+	case <-scanner.ready:
+		t.Fatal("expected background task not to be ready before the first compilation")
+	default:
+	}
+
+	w := scanner.writer(&discardWriter{})
+	_, _ = w.Write([]byte("[12:00:00 PM] Starting compilation in watch mode...\r\n\r\n"))
+	_, _ = w.Write([]byte("src/a.ts(1,1): error TS1005: ';' expected.\n[12:00:01 PM] Found 1 error. Watching"))
+	_, _ = w.Write([]byte(" for file changes.\n"))
+
+	select {
+	case <-scanner.ready:
+	default:
+		t.Fatal("expected background task to be ready after 'Watching for file changes'")
+	}
+	if len(scanner.Diagnostics()) != 1 {
+		t.Errorf("expected 1 diagnostic, got %d", len(scanner.Diagnostics()))
+	}
+
+	_, _ = w.Write([]byte("[12:00:05 PM] File change detected. Starting incremental compilation...\n"))
+	if len(scanner.Diagnostics()) != 0 {
+		t.Error("expected diagnostics to reset when a new compilation starts")
+	}
+}
+
+type discardWriter struct{}
+
+func (discardWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
@@ -0,0 +1,111 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+// outputScanner runs problem matchers over the output of a task and tracks
+// the readiness of background tasks.
+type outputScanner struct {
+	mu          sync.Mutex
+	states      []*matcherState
+	diagnostics []Diagnostic
+	ready       chan struct{}
+	readyOnce   sync.Once
+	// background is set when a matcher signals readiness through its endsPattern
+	background bool
+}
+
+func newOutputScanner(matchers []*ProblemMatcher, cwd string, workspaceDir string) *outputScanner {
+	scanner := &outputScanner{ready: make(chan struct{})}
+	hasBackground := false
+	for _, matcher := range matchers {
+		scanner.states = append(scanner.states, &matcherState{matcher: matcher, cwd: cwd, workspaceDir: workspaceDir})
+		if matcher.Background != nil && matcher.Background.EndsPattern != nil {
+			hasBackground = true
+		}
+	}
+	scanner.background = hasBackground
+	if !hasBackground {
+		// Nothing to wait for, the task counts as ready once started
+		scanner.markReady()
+	}
+	return scanner
+}
+
+func (s *outputScanner) markReady() {
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+// scanLine feeds one line of output to every matcher.
+func (s *outputScanner) scanLine(line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, state := range s.states {
+		if background := state.matcher.Background; background != nil {
+			if background.BeginsPattern != nil && background.BeginsPattern.MatchString(line) {
+				// A new build cycle starts; forget the problems of the previous one
+				s.diagnostics = nil
+			}
+			if background.EndsPattern != nil && background.EndsPattern.MatchString(line) {
+				s.markReady()
+			}
+		}
+
+		if diagnostic, ok := state.match(line); ok {
+			s.diagnostics = append(s.diagnostics, diagnostic)
+		}
+	}
+}
+
+// Diagnostics returns the problems found so far.
+func (s *outputScanner) Diagnostics() []Diagnostic {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Diagnostic(nil), s.diagnostics...)
+}
+
+// writer returns an io.Writer that copies output to dest and scans it line by line.
+func (s *outputScanner) writer(dest io.Writer) *lineWriter {
+	return &lineWriter{dest: dest, onLine: s.scanLine}
+}
+
+// lineWriter forwards writes to dest and calls onLine for every complete line.
+type lineWriter struct {
+	mu     sync.Mutex
+	dest   io.Writer
+	buf    bytes.Buffer
+	onLine func(line string)
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.dest.Write(p)
+	w.buf.Write(p)
+	for {
+		index := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if index < 0 {
+			break
+		}
+		line := string(w.buf.Next(index + 1))
+		w.onLine(strings.TrimRight(line, "\r\n"))
+	}
+	return n, err
+}
+
+// Flush passes a trailing line without newline to onLine.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.onLine(strings.TrimRight(w.buf.String(), "\r\n"))
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var supportedPackageManagers = []string{"npm", "yarn", "pnpm", "bun"}
+
+// lockFiles maps lockfile names to the package manager that writes them, in
+// the order they are checked.
+var lockFiles = []struct {
+	name           string
+	packageManager string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"package-lock.json", "npm"},
+}
+
+func isSupportedPackageManager(name string) bool {
+	for _, pm := range supportedPackageManagers {
+		if pm == name {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPackageManager picks the package manager for the package in packageDir.
+// The setting, VS Code's "npm.packageManager" ("auto", "npm", "yarn", "pnpm",
+// "bun"), wins unless it is "auto" or empty. Then come the "packageManager"
+// field of package.json and lockfiles found between packageDir and the
+// workspace root. Defaults to npm.
+func detectPackageManager(setting string, packageDir string, workspaceDir string) string {
+	if isSupportedPackageManager(setting) {
+		return setting
+	}
+
+	if pm := packageManagerFromPackageJSON(filepath.Join(packageDir, "package.json")); pm != "" {
+		return pm
+	}
+
+	if pm := packageManagerFromLockFiles(packageDir, workspaceDir); pm != "" {
+		return pm
+	}
+
+	return "npm"
+}
+
+// packageManagerFromPackageJSON reads the corepack "packageManager" field,
+// e.g. "pnpm@8.15.0".
+func packageManagerFromPackageJSON(packageFile string) string {
+	data, err := os.ReadFile(packageFile)
+	if err != nil {
+		return ""
+	}
+
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return ""
+	}
+
+	name, _, _ := strings.Cut(pkg.PackageManager, "@")
+	if isSupportedPackageManager(name) {
+		return name
+	}
+	return ""
+}
+
+// packageManagerFromLockFiles looks for a lockfile in packageDir and its
+// parents up to workspaceDir, since workspaces keep a single lockfile at the root.
+func packageManagerFromLockFiles(packageDir string, workspaceDir string) string {
+	dir := packageDir
+	for {
+		for _, lockFile := range lockFiles {
+			if _, err := os.Stat(filepath.Join(dir, lockFile.name)); err == nil {
+				return lockFile.packageManager
+			}
+		}
+
+		if rel, err := filepath.Rel(workspaceDir, dir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// buildPackageManagerArgs translates an npm script invocation into the
+// command line of the given package manager.
+func buildPackageManagerArgs(packageManager string, script string, extraArgs []string) []string {
+	if script == "install" {
+		return append([]string{"install"}, extraArgs...)
+	}
+
+	args := []string{"run", script}
+	if len(extraArgs) == 0 {
+		return args
+	}
+
+	// npm needs "--" to forward arguments to the script, the others forward them as-is
+	if packageManager == "npm" {
+		args = append(args, "--")
+	}
+	return append(args, extraArgs...)
+}
@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		setting  string
+		path     string
+		expected string
+	}{
+		{
+			name:     "defaults to npm",
+			files:    map[string]string{"package.json": `{}`},
+			expected: "npm",
+		},
+		{
+			name:     "pnpm lockfile",
+			files:    map[string]string{"package.json": `{}`, "pnpm-lock.yaml": ""},
+			expected: "pnpm",
+		},
+		{
+			name:     "yarn lockfile",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": ""},
+			expected: "yarn",
+		},
+		{
+			name:     "bun lockfile",
+			files:    map[string]string{"package.json": `{}`, "bun.lockb": ""},
+			expected: "bun",
+		},
+		{
+			name:     "lockfile at workspace root for nested package",
+			files:    map[string]string{"pnpm-lock.yaml": "", "packages/app/package.json": `{}`},
+			path:     "packages/app",
+			expected: "pnpm",
+		},
+		{
+			name:     "packageManager field wins over lockfile",
+			files:    map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`, "package-lock.json": "{}"},
+			expected: "yarn",
+		},
+		{
+			name:     "setting wins over detection",
+			files:    map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`, "yarn.lock": ""},
+			setting:  "bun",
+			expected: "bun",
+		},
+		{
+			name:     "unknown setting falls back to detection",
+			files:    map[string]string{"package.json": `{}`, "yarn.lock": ""},
+			setting:  "cnpm",
+			expected: "yarn",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceDir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(workspaceDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			packageDir := filepath.Join(workspaceDir, tt.path)
+			if got := detectPackageManager(tt.setting, packageDir, workspaceDir); got != tt.expected {
+				t.Errorf("detectPackageManager() = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildPackageManagerArgs(t *testing.T) {
+	tests := []struct {
+		name           string
+		packageManager string
+		script         string
+		extraArgs      []string
+		expected       []string
+	}{
+		{"npm run", "npm", "build", nil, []string{"run", "build"}},
+		{"npm run with args", "npm", "test", []string{"--watch"}, []string{"run", "test", "--", "--watch"}},
+		{"yarn run with args", "yarn", "test", []string{"--watch"}, []string{"run", "test", "--watch"}},
+		{"pnpm run with args", "pnpm", "test", []string{"--watch"}, []string{"run", "test", "--watch"}},
+		{"bun run", "bun", "dev", nil, []string{"run", "dev"}},
+		{"npm install", "npm", "install", nil, []string{"install"}},
+		{"pnpm install with args", "pnpm", "install", []string{"--frozen-lockfile"}, []string{"install", "--frozen-lockfile"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildPackageManagerArgs(tt.packageManager, tt.script, tt.extraArgs)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("buildPackageManagerArgs() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBuildNpmCommand_DetectedPackageManager(t *testing.T) {
+	workspaceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspaceDir, "pnpm-lock.yaml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &config.Task{Type: "npm", Script: "lint", Args: []string{"--fix"}}
+	cmd, err := buildNpmCommand(task, workspaceDir)
+	if err != nil {
+		t.Fatalf("buildNpmCommand failed: %v", err)
+	}
+
+	if filepath.Base(cmd.Path) != "pnpm" && cmd.Args[0] != "pnpm" {
+		t.Errorf("expected pnpm command, got %s", cmd.Path)
+	}
+	expectedArgs := []string{"run", "lint", "--fix"}
+	if !reflect.DeepEqual(cmd.Args[1:], expectedArgs) {
+		t.Errorf("expected args %v, got %v", expectedArgs, cmd.Args[1:])
+	}
+	if cmd.Dir != workspaceDir {
+		t.Errorf("expected dir %s, got %s", workspaceDir, cmd.Dir)
+	}
+}
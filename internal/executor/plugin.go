@@ -0,0 +1,227 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// PluginPrefix is the executable name prefix of task type plugins. A task of
+// type "bazel" is handled by a "tasks-json-cli-type-bazel" executable on PATH.
+const PluginPrefix = "tasks-json-cli-type-"
+
+// builtinTypes are the task types executed without a plugin.
+var builtinTypes = []string{"shell", "process", "npm", "typescript", "make", "cargo", "go", "gulp", "grunt"}
+
+// pluginRequest is written to the plugin's stdin as JSON.
+type pluginRequest struct {
+	Version         int                    `json:"version"`
+	Task            map[string]interface{} `json:"task"`
+	WorkspaceFolder string                 `json:"workspaceFolder"`
+	File            string                 `json:"file,omitempty"`
+}
+
+// pluginResolution is the plugin's answer to the "resolve" call. It either
+// describes the command line to run, or sets Run to execute the task itself.
+type pluginResolution struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Shell   bool              `json:"shell,omitempty"`
+	Cwd     string            `json:"cwd,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	Run     bool              `json:"run,omitempty"`
+}
+
+// BuiltinTypes returns the task types supported without plugins.
+func BuiltinTypes() []string {
+	return append([]string(nil), builtinTypes...)
+}
+
+func isBuiltinType(taskType string) bool {
+	for _, t := range builtinTypes {
+		if t == taskType {
+			return true
+		}
+	}
+	return false
+}
+
+// FindPlugin returns the path of the plugin executable for taskType.
+func FindPlugin(taskType string) (string, bool) {
+	if taskType == "" {
+		return "", false
+	}
+	path, err := exec.LookPath(PluginPrefix + taskType)
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// PluginTypes returns the task types provided by plugin executables on PATH.
+func PluginTypes() []string {
+	seen := make(map[string]bool)
+	var types []string
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, PluginPrefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			taskType := strings.TrimPrefix(name, PluginPrefix)
+			if taskType == "" || seen[taskType] || isBuiltinType(taskType) {
+				continue
+			}
+			if _, ok := FindPlugin(taskType); !ok {
+				continue
+			}
+			seen[taskType] = true
+			types = append(types, taskType)
+		}
+	}
+
+	sort.Strings(types)
+	return types
+}
+
+// SupportedTypes returns the built-in task types followed by plugin types.
+func SupportedTypes() []string {
+	return append(BuiltinTypes(), PluginTypes()...)
+}
+
+// IsSupportedType reports whether tasks of taskType can be executed.
+func IsSupportedType(taskType string) bool {
+	if isBuiltinType(taskType) {
+		return true
+	}
+	_, ok := FindPlugin(taskType)
+	return ok
+}
+
+func buildPluginRequest(task *config.Task, workspaceDir string, file string) ([]byte, error) {
+	definition, err := substitutedDefinition(task, workspaceDir, file)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(pluginRequest{
+		Version:         1,
+		Task:            definition,
+		WorkspaceFolder: workspaceDir,
+		File:            file,
+	})
+}
+
+// substitutedDefinition returns the task object as written in tasks.json, or
+// as encoded from task if it was not read from a file, with its variables
+// replaced.
+func substitutedDefinition(task *config.Task, workspaceDir string, file string) (map[string]interface{}, error) {
+	definition := task.Definition
+	if definition == nil {
+		data, err := json.Marshal(task)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return nil, err
+		}
+	}
+
+	substitute := func(value string) string {
+		return substituteVariables(&config.Task{Command: value}, workspaceDir, file).Command
+	}
+	return substituteDefinition(definition, substitute).(map[string]interface{}), nil
+}
+
+// substituteDefinition replaces the variables in every string of a task
+// definition, including nested objects and arrays. The definition itself is
+// left unchanged.
+func substituteDefinition(value interface{}, substitute func(string) string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return substitute(v)
+	case map[string]interface{}:
+		substituted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			substituted[key] = substituteDefinition(item, substitute)
+		}
+		return substituted
+	case []interface{}:
+		substituted := make([]interface{}, len(v))
+		for i, item := range v {
+			substituted[i] = substituteDefinition(item, substitute)
+		}
+		return substituted
+	default:
+		return value
+	}
+}
+
+// buildPluginCommand asks the plugin for task.Type how to run the task. The
+// plugin is called as "<plugin> resolve" and, if it chooses to run the task
+// itself, once more as "<plugin> run". Both receive the request on stdin.
+func buildPluginCommand(task *config.Task, workspaceDir string, file string) (*exec.Cmd, error) {
+	pluginPath, ok := FindPlugin(task.Type)
+	if !ok {
+		return nil, fmt.Errorf("unsupported task type: %s", task.Type)
+	}
+
+	request, err := buildPluginRequest(task, workspaceDir, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode task for plugin: %w", err)
+	}
+
+	resolveCmd := exec.Command(pluginPath, "resolve")
+	resolveCmd.Dir = workspaceDir
+	resolveCmd.Stdin = bytes.NewReader(request)
+	var stderr bytes.Buffer
+	resolveCmd.Stderr = &stderr
+	output, err := resolveCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s failed to resolve task: %w: %s", filepath.Base(pluginPath), err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resolution pluginResolution
+	if err := json.Unmarshal(output, &resolution); err != nil {
+		return nil, fmt.Errorf("plugin %s returned invalid response: %w", filepath.Base(pluginPath), err)
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case resolution.Run:
+		cmd = exec.Command(pluginPath, "run")
+		cmd.Stdin = bytes.NewReader(request)
+	case resolution.Command == "":
+		return nil, fmt.Errorf("plugin %s returned no command", filepath.Base(pluginPath))
+	case resolution.Shell:
+		cmd = buildShellCommand(&config.Task{Command: resolution.Command, Args: resolution.Args, Options: task.Options})
+	default:
+		cmd = exec.Command(resolution.Command, resolution.Args...)
+	}
+
+	cmd.Dir = workspaceDir
+	if resolution.Cwd != "" {
+		cmd.Dir = resolution.Cwd
+	}
+	if len(resolution.Env) > 0 {
+		cmd.Env = append(os.Environ(), buildEnvVars(resolution.Env)...)
+	}
+
+	return cmd, nil
+}
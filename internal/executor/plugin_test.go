@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// installPlugin writes a shell script plugin into a temporary PATH directory.
+func installPlugin(t *testing.T, taskType string, script string) string {
+	t.Helper()
+	pluginDir := t.TempDir()
+	pluginPath := filepath.Join(pluginDir, PluginPrefix+taskType)
+	if err := os.WriteFile(pluginPath, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", pluginDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return pluginDir
+}
+
+func TestPluginTypes(t *testing.T) {
+	installPlugin(t, "bazel", "exit 0\n")
+
+	if !IsSupportedType("bazel") {
+		t.Error("expected plugin type 'bazel' to be supported")
+	}
+	if IsSupportedType("gradle") {
+		t.Error("expected type without plugin to be unsupported")
+	}
+
+	found := false
+	for _, taskType := range PluginTypes() {
+		if taskType == "bazel" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected 'bazel' in plugin types, got %v", PluginTypes())
+	}
+}
+
+func TestBuildPluginCommand_Resolve(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "request.json")
+	installPlugin(t, "bazel", `cat > `+outputFile+`
+echo '{"command": "bazel", "args": ["build", "//app:all"], "env": {"CI": "1"}}'
+`)
+
+	task := &config.Task{}
+	if err := task.UnmarshalJSON([]byte(`{"label": "app", "type": "bazel", "target": "//app:all"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	workspaceDir := t.TempDir()
+	cmd, err := buildPluginCommand(task, workspaceDir, "")
+	if err != nil {
+		t.Fatalf("buildPluginCommand failed: %v", err)
+	}
+
+	if cmd.Args[0] != "bazel" || strings.Join(cmd.Args[1:], " ") != "build //app:all" {
+		t.Errorf("unexpected command: %v", cmd.Args)
+	}
+	if cmd.Dir != workspaceDir {
+		t.Errorf("expected dir %s, got %s", workspaceDir, cmd.Dir)
+	}
+	if cmd.Env[len(cmd.Env)-1] != "CI=1" {
+		t.Errorf("expected plugin env to be set, got %v", cmd.Env[len(cmd.Env)-1])
+	}
+
+	request, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(request), `"target":"//app:all"`) {
+		t.Errorf("expected custom task properties in plugin request, got %s", request)
+	}
+	if !strings.Contains(string(request), `"workspaceFolder":"`+workspaceDir+`"`) {
+		t.Errorf("expected workspace folder in plugin request, got %s", request)
+	}
+}
+
+func TestBuildPluginRequest_SubstitutesDefinition(t *testing.T) {
+	task := &config.Task{}
+	if err := task.UnmarshalJSON([]byte(`{"label": "app", "type": "bazel", "target": "${workspaceFolder}/app", "flags": ["--file=${relativeFile}", 3], "options": {"cwd": "${workspaceFolder}"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := buildPluginRequest(task, "/work", "/work/src/main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{`"target":"/work/app"`, `"flags":["--file=src/main.go",3]`, `"options":{"cwd":"/work"}`} {
+		if !strings.Contains(string(request), expected) {
+			t.Errorf("expected %s in plugin request, got %s", expected, request)
+		}
+	}
+	if task.Definition["target"] != "${workspaceFolder}/app" {
+		t.Errorf("expected the task definition to be left unchanged, got %v", task.Definition["target"])
+	}
+}
+
+func TestRunTask_PluginRunsItself(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "ran")
+	installPlugin(t, "custom", `if [ "$1" = "resolve" ]; then
+  echo '{"run": true}'
+else
+  cat > `+outputFile+`
+fi
+`)
+
+	task := &config.Task{Label: "custom", Type: "custom"}
+	if err := RunTask(task, t.TempDir(), ""); err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+
+	request, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("expected plugin to run the task: %v", err)
+	}
+	if !strings.Contains(string(request), `"label":"custom"`) {
+		t.Errorf("expected task definition on stdin, got %s", request)
+	}
+}
+
+func TestBuildPluginCommand_ResolveFailure(t *testing.T) {
+	installPlugin(t, "broken", "echo 'missing target' >&2\nexit 3\n")
+
+	_, err := buildPluginCommand(&config.Task{Type: "broken"}, t.TempDir(), "")
+	if err == nil {
+		t.Fatal("expected error from failing plugin")
+	}
+	if !strings.Contains(err.Error(), "missing target") {
+		t.Errorf("expected plugin stderr in error, got %v", err)
+	}
+}
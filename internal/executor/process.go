@@ -0,0 +1,349 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// defaultStopTimeout is how long a task gets to exit after an interrupt
+// before it is killed.
+const defaultStopTimeout = 5 * time.Second
+
+// runningTask is a started task process.
+type runningTask struct {
+	task    *config.Task
+	cmd     *exec.Cmd
+	scanner *outputScanner
+	// exited is closed when the process exits, done once its output is read
+	exited chan struct{}
+	done   chan struct{}
+	err    error
+	// grouped is set when the task runs in its own process group
+	grouped     bool
+	stopTimeout time.Duration
+	events      *EventBus
+	readyOnce   sync.Once
+	// stopped is set once the task is being stopped; its problems are not
+	// reported then
+	stopped atomic.Bool
+}
+
+// IsBackgroundTask reports whether a task keeps running after it has become
+// ready, like a watcher or a dev server.
+func IsBackgroundTask(task *config.Task) bool {
+	return task.IsBackground || (task.Type == "typescript" && task.Option == "watch")
+}
+
+// problemMatchersForTask returns the task's problem matchers, applying the
+// TypeScript defaults when none are configured.
+func problemMatchersForTask(task *config.Task) ([]*ProblemMatcher, error) {
+	if task.ProblemMatcher == nil && task.Type == "typescript" {
+		if task.Option == "watch" {
+			return ParseProblemMatchers("$tsc-watch")
+		}
+		return ParseProblemMatchers("$tsc")
+	}
+	return ParseProblemMatchers(task.ProblemMatcher)
+}
+
+// startTask builds the task's command and starts it without waiting. If ctx
+// can be cancelled, the task runs in its own process group and the whole
+// group is stopped when ctx is done.
+func startTask(ctx context.Context, task *config.Task, opts RunOptions) (*runningTask, error) {
+	workspaceDir, file := opts.WorkspaceDir, opts.File
+
+	if !IsSupportedType(task.Type) {
+		return nil, fmt.Errorf("unsupported task type: %s", task.Type)
+	}
+
+	// Check command requirements for specific task types
+	if (task.Type == "shell" || task.Type == "process") && task.Command == "" {
+		return nil, fmt.Errorf("task command is empty")
+	}
+
+	// Apply variable substitution
+	substitutedTask := substituteVariables(task, workspaceDir, file)
+
+	// Build command based on task type
+	cmd, err := buildCommandForTaskType(substitutedTask, workspaceDir, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if substitutedTask.Options != nil && substitutedTask.Options.Cwd != "" {
+		cmd.Dir = substitutedTask.Options.Cwd
+	}
+
+	if substitutedTask.Options != nil && substitutedTask.Options.Env != nil {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, buildEnvVars(substitutedTask.Options.Env)...)
+	}
+
+	// Like unknown matcher names, matchers that cannot be used are dropped
+	matchers, err := problemMatchersForTask(substitutedTask)
+	if err != nil {
+		opts.warn(task, "ignoring problemMatcher of task '%s': %v", task.Label, err)
+	}
+
+	cwd := cmd.Dir
+	if cwd == "" {
+		cwd = workspaceDir
+	}
+	scanner := newOutputScanner(matchers, cwd, workspaceDir)
+
+	running := &runningTask{
+		task:        task,
+		cmd:         cmd,
+		scanner:     scanner,
+		exited:      make(chan struct{}),
+		done:        make(chan struct{}),
+		stopTimeout: opts.StopTimeout,
+		events:      opts.Events,
+	}
+	if running.stopTimeout <= 0 {
+		running.stopTimeout = defaultStopTimeout
+	}
+
+	// Unless the run is silent, output goes to the terminal as it is written,
+	// including prompts and progress bars without a newline
+	var stdoutDest, stderrDest io.Writer = os.Stdout, os.Stderr
+	if opts.Silent {
+		stdoutDest, stderrDest = io.Discard, io.Discard
+	}
+	// A silent run owns the terminal, so its tasks read from /dev/null
+	if cmd.Stdin == nil && !opts.Silent {
+		cmd.Stdin = os.Stdin
+	}
+
+	var pipes []*os.File
+	var copies []func()
+	if len(matchers) == 0 && !opts.Events.wants(EventOutput) {
+		// Nobody reads the lines, so the task keeps the terminal
+		if !opts.Silent {
+			cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		}
+	} else {
+		// The lines are teed to the event bus and the problem matchers. Own
+		// pipes instead of cmd.Stdout writers, so that the process can be seen
+		// to exit while its children still hold the pipes open.
+		stdoutRead, stdoutWrite, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		stderrRead, stderrWrite, err := os.Pipe()
+		if err != nil {
+			stdoutRead.Close()
+			stdoutWrite.Close()
+			return nil, err
+		}
+		cmd.Stdout, cmd.Stderr = stdoutWrite, stderrWrite
+		pipes = []*os.File{stdoutRead, stderrRead}
+		defer stdoutWrite.Close()
+		defer stderrWrite.Close()
+
+		for _, stream := range []struct {
+			name string
+			r    *os.File
+			dest io.Writer
+		}{{"stdout", stdoutRead, stdoutDest}, {"stderr", stderrRead, stderrDest}} {
+			stream := stream
+			w := &lineWriter{dest: stream.dest, onLine: func(line string) {
+				opts.Events.publish(Event{Type: EventOutput, Task: task.Label, Stream: stream.name, Line: line})
+				scanner.scanLine(line)
+			}}
+			copies = append(copies, func() {
+				_, _ = io.Copy(w, stream.r)
+				w.Flush()
+			})
+		}
+	}
+
+	if ctx.Done() != nil {
+		setProcessGroup(cmd)
+		running.grouped = true
+	}
+
+	if err := cmd.Start(); err != nil {
+		for _, pipe := range pipes {
+			pipe.Close()
+		}
+		return nil, err
+	}
+	start := time.Now()
+	opts.Events.publish(Event{
+		Type:         EventTaskStarted,
+		Time:         start,
+		Task:         task.Label,
+		Command:      cmd.Path,
+		Args:         cmd.Args[1:],
+		Cwd:          cwd,
+		EnvKeys:      envKeys(substitutedTask),
+		PID:          cmd.Process.Pid,
+		Dependencies: task.GetDependencies(),
+		Background:   IsBackgroundTask(task),
+	})
+	// Output is read only now, so that its lines follow the start event
+	var readers sync.WaitGroup
+	for _, copyOutput := range copies {
+		copyOutput := copyOutput
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			copyOutput()
+		}()
+	}
+
+	go func() {
+		running.err = cmd.Wait()
+		close(running.exited)
+
+		// Children that outlive the task must not keep its output pipes open forever
+		outputRead := make(chan struct{})
+		go func() {
+			readers.Wait()
+			close(outputRead)
+		}()
+		select {
+		case <-outputRead:
+		case <-time.After(running.stopTimeout):
+			for _, pipe := range pipes {
+				pipe.Close()
+			}
+			<-outputRead
+		}
+		for _, pipe := range pipes {
+			pipe.Close()
+		}
+
+		stopped := running.stopped.Load()
+		if !stopped {
+			for _, d := range scanner.Diagnostics() {
+				d := d
+				opts.Events.publish(Event{Type: EventDiagnostic, Task: task.Label, Diagnostic: &d})
+			}
+		}
+		exitCode := -1
+		if cmd.ProcessState != nil {
+			exitCode = cmd.ProcessState.ExitCode()
+		}
+		finished := Event{Type: EventTaskFinished, Task: task.Label, ExitCode: &exitCode, Duration: time.Since(start), Stopped: stopped}
+		if running.err != nil {
+			finished.Error = running.err.Error()
+		}
+		opts.Events.publish(finished)
+		close(running.done)
+	}()
+
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				running.stop()
+			case <-running.done:
+			}
+		}()
+	}
+
+	return running, nil
+}
+
+// notifyReady calls onReady once a task with a background problem matcher
+// reports readiness. Tasks without one never do.
+func (r *runningTask) notifyReady(onReady func()) {
+	if onReady == nil || !r.scanner.background {
+		return
+	}
+	go func() {
+		select {
+		case <-r.scanner.ready:
+			r.publishReady()
+			onReady()
+		case <-r.done:
+		}
+	}()
+}
+
+// publishReady announces once that the task is ready.
+func (r *runningTask) publishReady() {
+	r.readyOnce.Do(func() {
+		r.events.publish(Event{Type: EventTaskReady, Task: r.task.Label})
+	})
+}
+
+// wait blocks until the task process exits.
+func (r *runningTask) wait() error {
+	<-r.done
+	return r.err
+}
+
+// waitReady blocks until a background task reports readiness through its
+// problem matcher, until it exits, or until ctx is done.
+func (r *runningTask) waitReady(ctx context.Context) error {
+	select {
+	case <-r.scanner.ready:
+		r.publishReady()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.done:
+		if r.err != nil {
+			return r.err
+		}
+		return nil
+	}
+}
+
+// stop interrupts the task and kills it if it does not exit in time.
+func (r *runningTask) stop() {
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+
+	r.stopped.Store(true)
+	_ = r.signal(os.Interrupt)
+	select {
+	case <-r.exited:
+		if r.grouped {
+			// Background jobs of a shell ignore interrupts; don't leave them behind
+			_ = r.signal(os.Kill)
+		}
+	case <-time.After(r.stopTimeout):
+		_ = r.signal(os.Kill)
+	}
+	<-r.done
+}
+
+// envKeys returns the sorted names of the environment variables a task sets.
+func envKeys(task *config.Task) []string {
+	if task.Options == nil {
+		return nil
+	}
+	var keys []string
+	for key := range task.Options.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// signal sends sig to the task, including its children when it has its own
+// process group.
+func (r *runningTask) signal(sig os.Signal) error {
+	if r.grouped {
+		return signalProcessGroup(r.cmd, sig)
+	}
+	return r.cmd.Process.Signal(sig)
+}
@@ -0,0 +1,254 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestRunTaskWithDependencies_BackgroundDependency(t *testing.T) {
+	workspaceDir := t.TempDir()
+	marker := filepath.Join(workspaceDir, "server-ready")
+
+	tasks := []config.Task{
+		{
+			Label:        "server",
+			Type:         "shell",
+			Command:      "echo starting; echo listening > " + marker + "; echo 'server ready'; exec sleep 30",
+			IsBackground: true,
+			ProblemMatcher: map[string]interface{}{
+				"pattern": map[string]interface{}{"regexp": "^never$"},
+				"background": map[string]interface{}{
+					"activeOnStart": true,
+					"beginsPattern": "starting",
+					"endsPattern":   "server ready",
+				},
+			},
+		},
+		{
+			Label:     "e2e",
+			Type:      "shell",
+			Command:   "test -f " + marker,
+			DependsOn: "server",
+		},
+	}
+
+	start := time.Now()
+	err := RunTaskWithDependencies(&tasks[1], tasks, workspaceDir, "")
+	if err != nil {
+		t.Fatalf("RunTaskWithDependencies failed: %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("expected background dependency to be stopped after the run")
+	}
+}
+
+func TestRunTaskWithDependencies_BackgroundDependencyWithoutMatcher(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "server", Type: "shell", Command: "exec sleep 30", IsBackground: true},
+		{Label: "e2e", Type: "shell", Command: "true", DependsOn: "server"},
+	}
+
+	// Without a background matcher the dependency counts as ready once started
+	err := RunTaskWithDependencies(&tasks[1], tasks, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("RunTaskWithDependencies failed: %v", err)
+	}
+}
+
+func TestExecuteTask_ReportsDiagnostics(t *testing.T) {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { os.Stderr = oldStderr }()
+
+	task := &config.Task{
+		Label:          "vet",
+		Type:           "shell",
+		Command:        "echo 'main.go:12:3: unreachable code'",
+		ProblemMatcher: "$go",
+	}
+	err := RunTask(task, t.TempDir(), "")
+
+	_ = w.Close()
+	output := make([]byte, 4096)
+	n, _ := r.Read(output)
+
+	if err != nil {
+		t.Fatalf("RunTask failed: %v", err)
+	}
+	if !strings.Contains(string(output[:n]), "Problems in task 'vet': 1 errors, 0 warnings") {
+		t.Errorf("expected problem summary, got %q", output[:n])
+	}
+	if !strings.Contains(string(output[:n]), "main.go:12:3: error: unreachable code") {
+		t.Errorf("expected diagnostic line, got %q", output[:n])
+	}
+}
+
+func TestExecuteTask_UnsupportedProblemMatcher(t *testing.T) {
+	oldStderr := os.Stderr
+	r, w, _ := os.Pipe()
+	os.Stderr = w
+	defer func() { os.Stderr = oldStderr }()
+
+	task := &config.Task{
+		Label:          "lint",
+		Type:           "shell",
+		Command:        "true",
+		ProblemMatcher: map[string]interface{}{"base": "$eslint-stylish"},
+	}
+	err := RunTask(task, t.TempDir(), "")
+
+	_ = w.Close()
+	output := make([]byte, 4096)
+	n, _ := r.Read(output)
+
+	if err != nil {
+		t.Fatalf("expected the task to run without the matcher, got %v", err)
+	}
+	if !strings.Contains(string(output[:n]), "Warning: ignoring problemMatcher of task 'lint'") {
+		t.Errorf("expected a warning about the dropped matcher, got %q", output[:n])
+	}
+}
+
+func TestIsBackgroundTask(t *testing.T) {
+	if !IsBackgroundTask(&config.Task{Type: "typescript", Option: "watch"}) {
+		t.Error("expected tsc watch task to be a background task")
+	}
+	if !IsBackgroundTask(&config.Task{Type: "shell", IsBackground: true}) {
+		t.Error("expected isBackground task to be a background task")
+	}
+	if IsBackgroundTask(&config.Task{Type: "typescript"}) {
+		t.Error("expected tsc build task not to be a background task")
+	}
+}
+
+func TestRunTaskWithDependenciesContext_Cancel(t *testing.T) {
+	workspaceDir := t.TempDir()
+	marker := filepath.Join(workspaceDir, "child-survived")
+
+	tasks := []config.Task{
+		{
+			Label: "slow",
+			Type:  "shell",
+			// The child shell outlives its parent unless the whole group is killed
+			Command: "(sleep 1 && touch " + marker + ") & sleep 30",
+		},
+		{Label: "after", Type: "shell", Command: "touch " + marker, DependsOn: "slow"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := RunTaskWithDependenciesContext(ctx, &tasks[1], tasks, workspaceDir, "")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 10*time.Second {
+		t.Error("expected the cancelled task to stop promptly")
+	}
+
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("expected the task's child processes to be killed and the dependent task skipped")
+	}
+}
+
+func TestRun_OnReady(t *testing.T) {
+	ready := make(chan struct{})
+	task := config.Task{
+		Label:        "server",
+		Type:         "shell",
+		Command:      "echo 'compiled successfully'; exec sleep 30",
+		IsBackground: true,
+		ProblemMatcher: map[string]interface{}{
+			"pattern": map[string]interface{}{"regexp": "^never$"},
+			"background": map[string]interface{}{
+				"beginsPattern": "compiling",
+				"endsPattern":   "compiled successfully",
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- Run(ctx, &task, []config.Task{task}, RunOptions{
+			WorkspaceDir: t.TempDir(),
+			StopTimeout:  time.Second,
+			OnReady:      func() { close(ready) },
+		})
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected OnReady to be called")
+	}
+
+	cancel()
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestRunAll_EveryTargetReportsReady(t *testing.T) {
+	server := config.Task{
+		Label:        "server",
+		Type:         "shell",
+		Command:      "echo 'compiled successfully'; exec sleep 30",
+		IsBackground: true,
+		ProblemMatcher: map[string]interface{}{
+			"pattern": map[string]interface{}{"regexp": "^never$"},
+			"background": map[string]interface{}{
+				"beginsPattern": "compiling",
+				"endsPattern":   "compiled successfully",
+			},
+		},
+	}
+	tasks := []config.Task{server, {Label: "e2e", Type: "shell", Command: "true"}}
+
+	ready := 0
+	err := RunAll(context.Background(), []*config.Task{&tasks[0], &tasks[1]}, tasks, RunOptions{
+		WorkspaceDir: t.TempDir(),
+		StopTimeout:  time.Second,
+		OnReady:      func() { ready++ },
+	})
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+	if ready != 1 {
+		t.Errorf("expected the server target to report ready once, got %d", ready)
+	}
+}
+
+func TestRunAll_SharedDependencyRunsOnce(t *testing.T) {
+	workspaceDir := t.TempDir()
+	log := filepath.Join(workspaceDir, "log")
+
+	tasks := []config.Task{
+		{Label: "gen", Type: "shell", Command: "echo gen >> " + log},
+		{Label: "build", Type: "shell", Command: "echo build >> " + log, DependsOn: "gen"},
+		{Label: "test", Type: "shell", Command: "echo test >> " + log, DependsOn: "gen"},
+	}
+
+	err := RunAll(context.Background(), []*config.Task{&tasks[1], &tasks[2]}, tasks, RunOptions{WorkspaceDir: workspaceDir})
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Fields(string(data)); strings.Join(got, " ") != "gen build test" {
+		t.Errorf("expected gen, build, test to run once each, got %v", got)
+	}
+}
@@ -0,0 +1,210 @@
+package executor
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Report collects the results of the tasks of a run, for export as JUnit XML
+// or SARIF.
+type Report struct {
+	mu      sync.Mutex
+	start   time.Time
+	tasks   []*TaskResult
+	byLabel map[string]*TaskResult
+}
+
+// TaskResult is the outcome of one task of a run.
+type TaskResult struct {
+	Label    string
+	Start    time.Time
+	Duration time.Duration
+	ExitCode int
+	Error    string
+	// Stopped is set for background tasks stopped at the end of the run
+	Stopped bool
+	// Skipped is "up to date" or "cached" for tasks that did not run
+	Skipped     string
+	Stdout      []string
+	Stderr      []string
+	Diagnostics []Diagnostic
+}
+
+// Failed reports whether the task exited by itself with an error.
+func (r TaskResult) Failed() bool {
+	return r.Skipped == "" && !r.Stopped && (r.ExitCode != 0 || r.Error != "")
+}
+
+// NewReport returns an empty report starting at the current time.
+func NewReport() *Report {
+	return &Report{start: time.Now(), byLabel: make(map[string]*TaskResult)}
+}
+
+// Handle records the events of a run; subscribe it to the run's EventBus.
+func (r *Report) Handle(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch event.Type {
+	case EventTaskStarted, EventTaskSkipped:
+		result := &TaskResult{Label: event.Task, Start: event.Time, Skipped: event.Status}
+		r.tasks = append(r.tasks, result)
+		r.byLabel[event.Task] = result
+		return
+	}
+
+	result := r.byLabel[event.Task]
+	if result == nil {
+		return
+	}
+	switch event.Type {
+	case EventOutput:
+		if event.Stream == "stderr" {
+			result.Stderr = append(result.Stderr, event.Line)
+		} else {
+			result.Stdout = append(result.Stdout, event.Line)
+		}
+	case EventDiagnostic:
+		result.Diagnostics = append(result.Diagnostics, *event.Diagnostic)
+	case EventTaskFinished:
+		result.Duration = event.Duration
+		if event.ExitCode != nil {
+			result.ExitCode = *event.ExitCode
+		}
+		result.Error = event.Error
+		result.Stopped = event.Stopped
+	}
+}
+
+// Tasks returns the results in start order.
+func (r *Report) Tasks() []TaskResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	results := make([]TaskResult, len(r.tasks))
+	for i, result := range r.tasks {
+		results[i] = *result
+	}
+	return results
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML: a test suite called name with
+// one test case per task.
+func (r *Report) WriteJUnit(w io.Writer, name string) error {
+	seconds := func(d time.Duration) string {
+		return fmt.Sprintf("%.3f", d.Seconds())
+	}
+
+	suite := junitTestSuite{Name: name, Timestamp: r.start.Format("2006-01-02T15:04:05")}
+	var total time.Duration
+	for _, result := range r.Tasks() {
+		testCase := junitTestCase{
+			Name:      result.Label,
+			Classname: name,
+			Time:      seconds(result.Duration),
+			SystemOut: joinLines(result.Stdout),
+			SystemErr: joinLines(result.Stderr),
+		}
+		switch {
+		case result.Skipped != "":
+			testCase.Skipped = &junitSkipped{Message: result.Skipped}
+			suite.Skipped++
+		case result.Failed():
+			testCase.Failure = junitFailureOf(result)
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		total += result.Duration
+	}
+	suite.Time = seconds(total)
+
+	suites := junitTestSuites{
+		Name:     "tasks-json-cli",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitFailureOf describes why a task failed, listing its problems if a
+// problem matcher found any.
+func junitFailureOf(result TaskResult) *junitFailure {
+	failure := &junitFailure{Message: fmt.Sprintf("exit code %d", result.ExitCode), Type: "exitCode"}
+	if result.ExitCode < 0 {
+		failure.Message = result.Error
+		failure.Type = "error"
+	}
+
+	var lines []string
+	for _, d := range result.Diagnostics {
+		lines = append(lines, d.String())
+	}
+	if len(lines) == 0 && result.Error != "" {
+		lines = append(lines, result.Error)
+	}
+	failure.Text = joinLines(lines)
+	return failure
+}
+
+func joinLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,114 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestReport_WriteJUnit(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "server", Type: "shell", Command: "exec sleep 30", IsBackground: true},
+		{Label: "gen", Type: "shell", Command: "echo generated; echo careful >&2"},
+		{Label: "vet", Type: "shell", Command: "echo 'main.go:12:3: unreachable code'; exit 1", ProblemMatcher: "$go", DependsOn: []interface{}{"server", "gen"}},
+	}
+	report := NewReport()
+	events := NewEventBus()
+	events.Subscribe(report.Handle)
+
+	if err := Run(context.Background(), &tasks[2], tasks, RunOptions{WorkspaceDir: t.TempDir(), Events: events}); err == nil {
+		t.Fatal("expected the failing task to fail the run")
+	}
+
+	var out bytes.Buffer
+	if err := report.WriteJUnit(&out, "vet"); err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+					Text    string `xml:",chardata"`
+				} `xml:"failure"`
+				SystemOut string `xml:"system-out"`
+				SystemErr string `xml:"system-err"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, out.String())
+	}
+	if parsed.Tests != 3 || parsed.Failures != 1 {
+		t.Fatalf("expected 3 tests and 1 failure, got %d and %d", parsed.Tests, parsed.Failures)
+	}
+
+	cases := parsed.Suites[0].Cases
+	if cases[0].Name != "server" || cases[0].Failure != nil {
+		t.Errorf("expected the stopped background task to pass, got %+v", cases[0])
+	}
+	if cases[1].SystemOut != "generated\n" || cases[1].SystemErr != "careful\n" {
+		t.Errorf("expected captured output, got %+v", cases[1])
+	}
+	failure := cases[2].Failure
+	if failure == nil || failure.Message != "exit code 1" || !strings.Contains(failure.Text, "main.go:12:3: error: unreachable code") {
+		t.Errorf("expected failure details, got %+v", failure)
+	}
+}
+
+func TestReport_WriteSARIF(t *testing.T) {
+	workspaceDir := t.TempDir()
+	report := NewReport()
+	report.Handle(Event{Type: EventTaskStarted, Task: "lint"})
+	report.Handle(Event{Type: EventDiagnostic, Task: "lint", Diagnostic: &Diagnostic{
+		Owner: "eslint", File: filepath.Join(workspaceDir, "src", "my app.ts"), Line: 3, Column: 7, Severity: "warning", Code: "no-unused-vars", Message: "x is unused",
+	}})
+	report.Handle(Event{Type: EventDiagnostic, Task: "lint", Diagnostic: &Diagnostic{
+		File: "/elsewhere/lib.ts", Severity: "info", Message: "deprecated",
+	}})
+
+	var out bytes.Buffer
+	if err := report.WriteSARIF(&out, workspaceDir); err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed sarifLog
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if parsed.Version != "2.1.0" || len(parsed.Runs) != 1 {
+		t.Fatalf("unexpected SARIF log %+v", parsed)
+	}
+	results := parsed.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	first := results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.RuleID != "no-unused-vars" || first.Level != "warning" || first.Properties["task"] != "lint" {
+		t.Errorf("unexpected result %+v", first)
+	}
+	if location.ArtifactLocation.URI != "src/my%20app.ts" || location.ArtifactLocation.URIBaseID != sarifWorkspace {
+		t.Errorf("expected a workspace-relative location, got %+v", location.ArtifactLocation)
+	}
+	if location.Region == nil || location.Region.StartLine != 3 || location.Region.StartColumn != 7 {
+		t.Errorf("unexpected region %+v", location.Region)
+	}
+
+	second := results[1].Locations[0].PhysicalLocation
+	if results[1].Level != "note" || second.ArtifactLocation.URI != "file:///elsewhere/lib.ts" || second.Region != nil {
+		t.Errorf("unexpected result outside the workspace %+v", results[1])
+	}
+}
@@ -1,60 +1,101 @@
 package executor
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
 )
 
-func executeTask(task *config.Task, workspaceDir string, file string) error {
-	supportedTypes := []string{"shell", "process", "npm", "typescript"}
-	isSupported := false
-	for _, t := range supportedTypes {
-		if task.Type == t {
-			isSupported = true
-			break
-		}
-	}
-	
-	if !isSupported {
-		return fmt.Errorf("unsupported task type: %s", task.Type)
+func executeTask(ctx context.Context, task *config.Task, opts RunOptions) error {
+	running, err := startTask(ctx, task, opts)
+	if err != nil {
+		return err
 	}
+	running.notifyReady(opts.OnReady)
 
-	// Check command requirements for specific task types
-	if (task.Type == "shell" || task.Type == "process") && task.Command == "" {
-		return fmt.Errorf("task command is empty")
+	err = running.wait()
+	if ctx.Err() != nil {
+		// The process died from our own signal; report why it was stopped
+		return ctx.Err()
 	}
+	return err
+}
 
-	// Apply variable substitution
-	substitutedTask := substituteVariables(task, workspaceDir, file)
-	
-	// Build command based on task type
-	cmd, err := buildCommandForTaskType(substitutedTask, workspaceDir)
+// executeCachedTask restores the task's outputs and stdout from the cache if
+// an entry for its current key exists, and otherwise runs it and stores the
+// result.
+func executeCachedTask(ctx context.Context, task *config.Task, resolver *DependencyResolver, opts RunOptions, keys map[string]string) error {
+	key, err := cacheKey(task, resolver, opts, keys)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to compute cache key: %w", err)
 	}
-	
-	if substitutedTask.Options != nil && substitutedTask.Options.Cwd != "" {
-		cmd.Dir = substitutedTask.Options.Cwd
+
+	if entry, ok := opts.Cache.Lookup(key); ok {
+		var log bytes.Buffer
+		err := opts.Cache.restore(entry, opts.WorkspaceDir, &log)
+		if err == nil {
+			opts.Events.publish(Event{Type: EventTaskSkipped, Task: task.Label, Dependencies: task.GetDependencies(), Status: "cached"})
+			var dest io.Writer = os.Stdout
+			if opts.Silent {
+				dest = io.Discard
+			}
+			replay := &lineWriter{dest: dest, onLine: func(line string) {
+				opts.Events.publish(Event{Type: EventOutput, Task: task.Label, Stream: "stdout", Line: line})
+			}}
+			_, _ = replay.Write(log.Bytes())
+			replay.Flush()
+			return nil
+		}
+		opts.warn(task, "failed to restore task '%s' from cache, running it: %v", task.Label, err)
 	}
 
-	if substitutedTask.Options != nil && substitutedTask.Options.Env != nil {
-		cmd.Env = append(os.Environ(), buildEnvVars(substitutedTask.Options.Env)...)
+	// Log the task's stdout for replaying it on a cache hit
+	var log bytes.Buffer
+	unsubscribe := opts.Events.Subscribe(func(event Event) {
+		if event.Type == EventOutput && event.Task == task.Label && event.Stream == "stdout" {
+			log.WriteString(event.Line + "\n")
+		}
+	})
+	start := time.Now()
+	err = executeTask(ctx, task, opts)
+	unsubscribe()
+	if err != nil {
+		return err
 	}
 
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
+	outputs, err := discovery.ExpandOutputs(opts.WorkspaceDir, task.Outputs)
+	if err == nil {
+		entry := CacheEntry{Key: key, Label: task.Label, Created: time.Now(), Duration: time.Since(start), Outputs: outputs}
+		err = opts.Cache.store(entry, log.Bytes(), opts.WorkspaceDir)
+	}
+	if err != nil {
+		opts.warn(task, "failed to cache task '%s': %v", task.Label, err)
+	}
+	return nil
+}
 
-	return cmd.Run()
+// ResolveCommand returns the command line a task runs, executable first,
+// after variable substitution.
+func ResolveCommand(task *config.Task, workspaceDir string, file string) ([]string, error) {
+	substituted := substituteVariables(task, workspaceDir, file)
+	cmd, err := buildCommandForTaskType(substituted, workspaceDir, file)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{cmd.Path}, cmd.Args[1:]...), nil
 }
 
-func buildCommandForTaskType(task *config.Task, workspaceDir string) (*exec.Cmd, error) {
+func buildCommandForTaskType(task *config.Task, workspaceDir string, file string) (*exec.Cmd, error) {
 	switch task.Type {
 	case "shell":
 		return buildShellCommand(task), nil
@@ -64,8 +105,16 @@ func buildCommandForTaskType(task *config.Task, workspaceDir string) (*exec.Cmd,
 		return buildNpmCommand(task, workspaceDir)
 	case "typescript":
 		return buildTypescriptCommand(task, workspaceDir)
+	case "make":
+		return buildMakeCommand(task, workspaceDir)
+	case "cargo":
+		return buildCargoCommand(task, workspaceDir)
+	case "go":
+		return buildGoCommand(task, workspaceDir)
+	case "gulp", "grunt":
+		return buildTaskRunnerCommand(task.Type, task, workspaceDir)
 	default:
-		return nil, fmt.Errorf("unsupported task type: %s", task.Type)
+		return buildPluginCommand(task, workspaceDir, file)
 	}
 }
 
@@ -110,19 +159,11 @@ func buildNpmCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error)
 		return nil, fmt.Errorf("npm task requires 'script' field")
 	}
 	
-	args := []string{"run", task.Script}
-	cmd := exec.Command("npm", args...)
-	
-	// Set working directory to task path or workspace directory
-	if task.Path != "" {
-		if filepath.IsAbs(task.Path) {
-			cmd.Dir = task.Path
-		} else {
-			cmd.Dir = filepath.Join(workspaceDir, task.Path)
-		}
-	} else {
-		cmd.Dir = workspaceDir
-	}
+	packageDir := resolveTaskDir(task, workspaceDir)
+	packageManager := detectPackageManager(task.PackageManager, packageDir, workspaceDir)
+	args := buildPackageManagerArgs(packageManager, task.Script, task.Args)
+	cmd := exec.Command(packageManager, args...)
+	cmd.Dir = packageDir
 	
 	return cmd, nil
 }
@@ -130,26 +171,44 @@ func buildNpmCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error)
 func buildTypescriptCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error) {
 	var args []string
 	
-	// Add tsconfig if specified
-	if task.TSConfig != "" {
+	// Add tsconfig if specified; build mode (-b) also builds referenced projects
+	if task.Option == "build" {
+		args = append(args, "-b")
+		if task.TSConfig != "" {
+			args = append(args, task.TSConfig)
+		}
+	} else if task.TSConfig != "" {
 		args = append(args, "-p", task.TSConfig)
 	}
 	
 	// Add option if specified (e.g., "watch")
-	if task.Option != "" {
-		if task.Option == "watch" {
-			args = append(args, "--watch")
-		} else {
-			args = append(args, task.Option)
-		}
+	switch task.Option {
+	case "", "build":
+	case "watch":
+		args = append(args, "--watch")
+	default:
+		args = append(args, task.Option)
 	}
 	
-	cmd := exec.Command("tsc", args...)
+	// Prefer the compiler installed next to the project over a global one
+	tsconfigDir := workspaceDir
+	if task.TSConfig != "" {
+		tsconfigDir = filepath.Dir(resolveTSConfigPath(task.TSConfig, workspaceDir))
+	}
+	
+	cmd := exec.Command(findLocalBin("tsc", tsconfigDir, workspaceDir), args...)
 	cmd.Dir = workspaceDir
 	
 	return cmd, nil
 }
 
+func resolveTSConfigPath(tsconfig string, workspaceDir string) string {
+	if filepath.IsAbs(tsconfig) {
+		return tsconfig
+	}
+	return filepath.Join(workspaceDir, tsconfig)
+}
+
 func buildEnvVars(envMap map[string]string) []string {
 	var envVars []string
 	for key, value := range envMap {
@@ -362,24 +421,174 @@ func substituteVariables(task *config.Task, workspaceDir string, file string) *c
 	return &substituted
 }
 
+// RunOptions configures how a task and its dependencies are run.
+type RunOptions struct {
+	WorkspaceDir string
+	// File replaces the ${file} variables
+	File string
+	// StopTimeout is how long a cancelled or stopped task gets to exit after
+	// an interrupt before it is killed. Defaults to 5 seconds.
+	StopTimeout time.Duration
+	// OnReady is called when the target task reports through the background
+	// problem matcher that it is ready, e.g. a dev server that is listening.
+	OnReady func()
+	// Cache, if set, stores and restores the results of cacheable tasks
+	Cache *Cache
+	// Force runs tasks whose outputs are up to date, too
+	Force bool
+	// Events, if set, receives the events of the run. Unless Silent is set,
+	// task output is passed through to the terminal and problems are printed.
+	Events *EventBus
+	// Silent leaves showing the run to the subscribers of Events, e.g. a
+	// full-screen view that must not be written over
+	Silent bool
+}
+
+// warn reports a problem that does not fail the task, on stderr or, for
+// silent runs, as stderr output of the task.
+func (opts RunOptions) warn(task *config.Task, format string, args ...interface{}) {
+	message := "Warning: " + fmt.Sprintf(format, args...)
+	if opts.Silent {
+		opts.Events.publish(Event{Type: EventOutput, Task: task.Label, Stream: "stderr", Line: message})
+		return
+	}
+	fmt.Fprintln(os.Stderr, message)
+}
+
 func RunTask(task *config.Task, workspaceDir string, file string) error {
-	return executeTask(task, workspaceDir, file)
+	opts, unsubscribe := withEvents(RunOptions{WorkspaceDir: workspaceDir, File: file})
+	defer unsubscribe()
+	return executeTask(context.Background(), task, opts)
 }
 
 func RunTaskWithDependencies(task *config.Task, allTasks []config.Task, workspaceDir string, file string) error {
+	return RunTaskWithDependenciesContext(context.Background(), task, allTasks, workspaceDir, file)
+}
+
+// RunTaskWithDependenciesContext is like RunTaskWithDependencies, but stops the
+// running task and skips the remaining ones when ctx is cancelled. Each task
+// then runs in its own process group, which is killed as a whole.
+func RunTaskWithDependenciesContext(ctx context.Context, task *config.Task, allTasks []config.Task, workspaceDir string, file string) error {
+	return Run(ctx, task, allTasks, RunOptions{WorkspaceDir: workspaceDir, File: file})
+}
+
+// Run executes task after its dependencies, as configured by opts. It stops
+// when ctx is cancelled, like RunTaskWithDependenciesContext.
+func Run(ctx context.Context, task *config.Task, allTasks []config.Task, opts RunOptions) error {
+	return RunAll(ctx, []*config.Task{task}, allTasks, opts)
+}
+
+// RunAll executes several tasks after their dependencies. Dependencies shared
+// between the tasks run only once.
+func RunAll(ctx context.Context, targets []*config.Task, allTasks []config.Task, opts RunOptions) error {
+	opts, unsubscribe := withEvents(opts)
+	defer unsubscribe()
+
+	start := time.Now()
+	err := runAll(ctx, targets, allTasks, opts)
+
+	finished := Event{Type: EventRunFinished, Status: "succeeded", Duration: time.Since(start)}
+	if err != nil {
+		finished.Status = "failed"
+		if ctx.Err() != nil {
+			finished.Status = "cancelled"
+		}
+		finished.Error = err.Error()
+	}
+	opts.Events.publish(finished)
+	return err
+}
+
+func runAll(ctx context.Context, targets []*config.Task, allTasks []config.Task, opts RunOptions) error {
 	resolver := NewDependencyResolver(allTasks)
 	
-	executionOrder, err := resolver.ResolveExecutionOrder(task.Label)
-	if err != nil {
-		return fmt.Errorf("failed to resolve dependencies: %w", err)
+	var executionOrder []*config.Task
+	seen := make(map[string]bool)
+	isTarget := make(map[string]bool)
+	for _, target := range targets {
+		isTarget[target.Label] = true
+		order, err := resolver.ResolveExecutionOrder(target.Label)
+		if err != nil {
+			return fmt.Errorf("failed to resolve dependencies: %w", err)
+		}
+		for _, t := range order {
+			if !seen[t.Label] {
+				seen[t.Label] = true
+				executionOrder = append(executionOrder, t)
+			}
+		}
 	}
 	
+	plan := Event{Type: EventPlan}
 	for _, t := range executionOrder {
-		err := executeTask(t, workspaceDir, file)
+		plan.Tasks = append(plan.Tasks, t.Label)
+	}
+	opts.Events.publish(plan)
+	
+	// Background dependencies keep running until the whole run is over
+	var background []*runningTask
+	defer func() {
+		for _, running := range background {
+			running.stop()
+		}
+	}()
+	
+	// Keys of the cacheable tasks, computed once their dependencies have run
+	cacheKeys := make(map[string]string)
+	// Tasks that were not skipped as up to date
+	ran := make(map[string]bool)
+	
+	// Only the targets report readiness
+	dependencyOpts := opts
+	dependencyOpts.OnReady = nil
+	
+	for i, t := range executionOrder {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		
+		taskOpts := dependencyOpts
+		if isTarget[t.Label] {
+			taskOpts = opts
+		}
+		
+		// A background task keeps running while the tasks after it run. The
+		// last task of the run is waited for instead.
+		if i < len(executionOrder)-1 && IsBackgroundTask(t) {
+			running, err := startTask(ctx, t, taskOpts)
+			if err != nil {
+				return fmt.Errorf("failed to execute task '%s': %w", t.Label, err)
+			}
+			background = append(background, running)
+			ran[t.Label] = true
+			if err := running.waitReady(ctx); err != nil {
+				return fmt.Errorf("failed to execute task '%s': %w", t.Label, err)
+			}
+			if taskOpts.OnReady != nil {
+				taskOpts.OnReady()
+			}
+			continue
+		}
+		
+		// Cached tasks are skipped through the cache instead
+		if !opts.Force && !t.Cache {
+			if upToDate, reason := CheckUpToDate(t, ran, opts.WorkspaceDir); upToDate {
+				opts.Events.publish(Event{Type: EventTaskSkipped, Task: t.Label, Dependencies: t.GetDependencies(), Status: "up to date", Reason: reason})
+				continue
+			}
+		}
+		ran[t.Label] = true
+		
+		var err error
+		if opts.Cache != nil && IsCacheable(t) {
+			err = executeCachedTask(ctx, t, resolver, taskOpts, cacheKeys)
+		} else {
+			err = executeTask(ctx, t, taskOpts)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to execute task '%s': %w", t.Label, err)
 		}
 	}
 	
 	return nil
-}
\ No newline at end of file
+}
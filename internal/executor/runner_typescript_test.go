@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"strings"
 
@@ -98,4 +100,81 @@ func TestBuildTypescriptCommand(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestBuildTypescriptCommand_BuildMode(t *testing.T) {
+	workspaceDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workspaceDir, "tsconfig.json"), []byte(`{
+		// Solution-style config
+		"files": [],
+		"references": [{"path": "./packages/core"}],
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		task         *config.Task
+		expectedArgs []string
+	}{
+		{
+			name:         "explicit build option",
+			task:         &config.Task{Type: "typescript", TSConfig: "other.json", Option: "build"},
+			expectedArgs: []string{"-b", "other.json"},
+		},
+		{
+			name:         "project references alone keep project mode",
+			task:         &config.Task{Type: "typescript", TSConfig: "tsconfig.json"},
+			expectedArgs: []string{"-p", "tsconfig.json"},
+		},
+		{
+			name:         "watch with project references",
+			task:         &config.Task{Type: "typescript", TSConfig: "tsconfig.json", Option: "watch"},
+			expectedArgs: []string{"-p", "tsconfig.json", "--watch"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := buildTypescriptCommand(tt.task, workspaceDir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(cmd.Args[1:], " ") != strings.Join(tt.expectedArgs, " ") {
+				t.Errorf("expected args %v, got %v", tt.expectedArgs, cmd.Args[1:])
+			}
+		})
+	}
+}
+
+func TestBuildTypescriptCommand_LocalCompiler(t *testing.T) {
+	workspaceDir := t.TempDir()
+	projectDir := filepath.Join(workspaceDir, "packages", "web")
+	binDir := filepath.Join(projectDir, "node_modules", ".bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	localTsc := filepath.Join(binDir, "tsc")
+	if err := os.WriteFile(localTsc, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	task := &config.Task{Type: "typescript", TSConfig: "packages/web/tsconfig.json"}
+	cmd, err := buildTypescriptCommand(task, workspaceDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.Path != localTsc {
+		t.Errorf("expected local compiler %s, got %s", localTsc, cmd.Path)
+	}
+
+	// Projects without a local install fall back to tsc on PATH
+	task = &config.Task{Type: "typescript", TSConfig: "tsconfig.json"}
+	cmd, err = buildTypescriptCommand(task, workspaceDir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cmd.Args[0] != "tsc" {
+		t.Errorf("expected global tsc, got %s", cmd.Args[0])
+	}
+}
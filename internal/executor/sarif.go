@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// sarifWorkspace is the base id of locations inside the workspace folder
+const sarifWorkspace = "WORKSPACE"
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactURI `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+}
+
+type sarifArtifactURI struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId,omitempty"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactURI `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// WriteSARIF writes the problems found by the problem matchers as SARIF 2.1.0
+// results. Files inside workspaceDir are given relative to it.
+func (r *Report) WriteSARIF(w io.Writer, workspaceDir string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "tasks-json-cli",
+			InformationURI: "https://github.com/garaemon/tasks-json-cli",
+		}},
+		Results: []sarifResult{},
+	}
+	if workspaceDir != "" {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactURI{
+			sarifWorkspace: {URI: fileURI(workspaceDir) + "/"},
+		}
+	}
+
+	for _, result := range r.Tasks() {
+		for _, d := range result.Diagnostics {
+			properties := map[string]string{"task": result.Label}
+			if d.Owner != "" {
+				properties["owner"] = d.Owner
+			}
+			if d.Source != "" {
+				properties["source"] = d.Source
+			}
+			sarif := sarifResult{
+				RuleID:     d.Code,
+				Level:      sarifLevel(d.Severity),
+				Message:    sarifMessage{Text: d.Message},
+				Properties: properties,
+			}
+			if d.File != "" {
+				location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact(d.File, workspaceDir)}}
+				if d.Line > 0 {
+					location.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column, EndLine: d.EndLine, EndColumn: d.EndColumn}
+				}
+				sarif.Locations = []sarifLocation{location}
+			}
+			run.Results = append(run.Results, sarif)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{Version: "2.1.0", Schema: sarifSchema, Runs: []sarifRun{run}})
+}
+
+// sarifLevel maps a problem matcher severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "error", "warning":
+		return severity
+	case "info":
+		return "note"
+	}
+	return "none"
+}
+
+func sarifArtifact(file string, workspaceDir string) sarifArtifactURI {
+	if workspaceDir != "" {
+		if rel, err := filepath.Rel(workspaceDir, file); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return sarifArtifactURI{URI: (&url.URL{Path: filepath.ToSlash(rel)}).String(), URIBaseID: sarifWorkspace}
+		}
+	}
+	return sarifArtifactURI{URI: fileURI(file)}
+}
+
+func fileURI(path string) string {
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		// Windows drive letters
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: strings.TrimSuffix(path, "/")}).String()
+}
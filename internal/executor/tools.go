@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// resolveTaskDir returns the directory of a task's "path" property, relative
+// to the workspace unless it is absolute.
+func resolveTaskDir(task *config.Task, workspaceDir string) string {
+	if task.Path == "" {
+		return workspaceDir
+	}
+	if filepath.IsAbs(task.Path) {
+		return task.Path
+	}
+	return filepath.Join(workspaceDir, task.Path)
+}
+
+// findLocalBin looks for a node_modules/.bin executable in dir and its parents
+// up to the workspace root, falling back to the bare name to search PATH.
+func findLocalBin(name string, dir string, workspaceDir string) string {
+	current := dir
+	for {
+		candidate := filepath.Join(current, "node_modules", ".bin", name)
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate
+		}
+
+		if rel, err := filepath.Rel(workspaceDir, current); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return name
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return name
+		}
+		current = parent
+	}
+}
+
+func buildMakeCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error) {
+	var args []string
+	if task.Target != "" {
+		args = append(args, task.Target)
+	}
+	args = append(args, task.Args...)
+
+	cmd := exec.Command("make", args...)
+	cmd.Dir = resolveTaskDir(task, workspaceDir)
+	return cmd, nil
+}
+
+func buildCargoCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error) {
+	if task.Command == "" {
+		return nil, fmt.Errorf("cargo task requires 'command' field")
+	}
+
+	args := append([]string{task.Command}, task.Args...)
+	cmd := exec.Command("cargo", args...)
+	cmd.Dir = resolveTaskDir(task, workspaceDir)
+	return cmd, nil
+}
+
+func buildGoCommand(task *config.Task, workspaceDir string) (*exec.Cmd, error) {
+	if task.Command == "" {
+		return nil, fmt.Errorf("go task requires 'command' field")
+	}
+
+	args := append([]string{task.Command}, task.Args...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = resolveTaskDir(task, workspaceDir)
+	return cmd, nil
+}
+
+// buildTaskRunnerCommand builds gulp and grunt invocations, preferring the
+// runner installed in the project's node_modules.
+func buildTaskRunnerCommand(runner string, task *config.Task, workspaceDir string) (*exec.Cmd, error) {
+	if task.TaskName == "" {
+		return nil, fmt.Errorf("%s task requires 'task' field", runner)
+	}
+
+	dir := resolveTaskDir(task, workspaceDir)
+	var args []string
+	if task.File != "" {
+		args = append(args, "--gulpfile", task.File)
+		if runner == "grunt" {
+			args[0] = "--gruntfile"
+		}
+	}
+	args = append(args, task.TaskName)
+	args = append(args, task.Args...)
+
+	cmd := exec.Command(findLocalBin(runner, dir, workspaceDir), args...)
+	cmd.Dir = dir
+	return cmd, nil
+}
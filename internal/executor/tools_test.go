@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestBuildCommandForTaskType_DetectedTypes(t *testing.T) {
+	workspaceDir := "/workspace"
+
+	tests := []struct {
+		name         string
+		task         *config.Task
+		expectedCmd  string
+		expectedArgs []string
+		expectedDir  string
+		expectError  bool
+	}{
+		{
+			name:         "make target",
+			task:         &config.Task{Type: "make", Target: "build", Path: "docs"},
+			expectedCmd:  "make",
+			expectedArgs: []string{"build"},
+			expectedDir:  filepath.Join(workspaceDir, "docs"),
+		},
+		{
+			name:         "make default target",
+			task:         &config.Task{Type: "make"},
+			expectedCmd:  "make",
+			expectedArgs: []string{},
+			expectedDir:  workspaceDir,
+		},
+		{
+			name:         "cargo command with args",
+			task:         &config.Task{Type: "cargo", Command: "test", Args: []string{"--release"}},
+			expectedCmd:  "cargo",
+			expectedArgs: []string{"test", "--release"},
+			expectedDir:  workspaceDir,
+		},
+		{
+			name:         "go command",
+			task:         &config.Task{Type: "go", Command: "run", Args: []string{"./cmd/tool"}, Path: "tools"},
+			expectedCmd:  "go",
+			expectedArgs: []string{"run", "./cmd/tool"},
+			expectedDir:  filepath.Join(workspaceDir, "tools"),
+		},
+		{
+			name:         "gulp task",
+			task:         &config.Task{Type: "gulp", TaskName: "build", File: "gulpfile.ts"},
+			expectedCmd:  "gulp",
+			expectedArgs: []string{"--gulpfile", "gulpfile.ts", "build"},
+			expectedDir:  workspaceDir,
+		},
+		{
+			name:         "grunt task",
+			task:         &config.Task{Type: "grunt", TaskName: "concat"},
+			expectedCmd:  "grunt",
+			expectedArgs: []string{"concat"},
+			expectedDir:  workspaceDir,
+		},
+		{
+			name:        "cargo without command",
+			task:        &config.Task{Type: "cargo"},
+			expectError: true,
+		},
+		{
+			name:        "gulp without task",
+			task:        &config.Task{Type: "gulp"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := buildCommandForTaskType(tt.task, workspaceDir, "")
+			if tt.expectError {
+				if err == nil {
+					t.Error("expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if cmd.Args[0] != tt.expectedCmd {
+				t.Errorf("expected command %s, got %s", tt.expectedCmd, cmd.Args[0])
+			}
+			if !reflect.DeepEqual(cmd.Args[1:], tt.expectedArgs) {
+				t.Errorf("expected args %v, got %v", tt.expectedArgs, cmd.Args[1:])
+			}
+			if cmd.Dir != tt.expectedDir {
+				t.Errorf("expected dir %s, got %s", tt.expectedDir, cmd.Dir)
+			}
+		})
+	}
+}
+
+func TestFindLocalBin(t *testing.T) {
+	workspaceDir := t.TempDir()
+	binDir := filepath.Join(workspaceDir, "node_modules", ".bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "gulp"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	packageDir := filepath.Join(workspaceDir, "packages", "web")
+	if got := findLocalBin("gulp", packageDir, workspaceDir); got != filepath.Join(binDir, "gulp") {
+		t.Errorf("expected local gulp, got %s", got)
+	}
+	if got := findLocalBin("grunt", packageDir, workspaceDir); got != "grunt" {
+		t.Errorf("expected fallback to PATH, got %s", got)
+	}
+}
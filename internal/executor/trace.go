@@ -0,0 +1,237 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Trace records when the tasks of a run start and finish, for export in the
+// Chrome trace-event format read by Perfetto and chrome://tracing.
+type Trace struct {
+	mu    sync.Mutex
+	start time.Time
+	spans []*TraceSpan
+	// running holds the spans of the tasks that have not finished yet
+	running map[string]*TraceSpan
+	// lanes holds whether each concurrency lane is busy
+	lanes []bool
+}
+
+// TraceSpan is the execution of one task.
+type TraceSpan struct {
+	Label        string
+	Dependencies []string
+	PID          int
+	// Lane is the 1-based concurrency lane: tasks that overlap in time, like
+	// background dependencies, get different lanes
+	Lane     int
+	Start    time.Time
+	End      time.Time
+	ExitCode int
+	// Ready is when a background task reported readiness
+	Ready time.Time
+	// Status is "cached" or "up to date" for tasks that did not run
+	Status string
+}
+
+// NewTrace starts a trace at the current time.
+func NewTrace() *Trace {
+	return &Trace{start: time.Now(), running: make(map[string]*TraceSpan)}
+}
+
+// Handle records the events of a run; subscribe it to the run's EventBus.
+func (t *Trace) Handle(event Event) {
+	switch event.Type {
+	case EventTaskStarted:
+		t.begin(event.Task, event.Dependencies, event.PID, event.Time)
+	case EventTaskReady:
+		t.mu.Lock()
+		if span := t.running[event.Task]; span != nil && span.Ready.IsZero() {
+			span.Ready = event.Time
+		}
+		t.mu.Unlock()
+	case EventTaskFinished:
+		exitCode := -1
+		if event.ExitCode != nil {
+			exitCode = *event.ExitCode
+		}
+		t.end(event.Task, exitCode, event.Time)
+	case EventTaskSkipped:
+		span := t.begin(event.Task, event.Dependencies, 0, event.Time)
+		span.Status = event.Status
+		t.end(event.Task, 0, event.Time)
+	}
+}
+
+// begin records the start of a task on the first free lane.
+func (t *Trace) begin(label string, dependencies []string, pid int, at time.Time) *TraceSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lane := 0
+	for lane < len(t.lanes) && t.lanes[lane] {
+		lane++
+	}
+	if lane == len(t.lanes) {
+		t.lanes = append(t.lanes, false)
+	}
+	t.lanes[lane] = true
+
+	span := &TraceSpan{
+		Label:        label,
+		Dependencies: dependencies,
+		PID:          pid,
+		Lane:         lane + 1,
+		Start:        at,
+	}
+	t.spans = append(t.spans, span)
+	t.running[label] = span
+	return span
+}
+
+// end records the exit of a task and frees its lane.
+func (t *Trace) end(label string, exitCode int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := t.running[label]
+	if span == nil {
+		return
+	}
+	delete(t.running, label)
+	span.End = at
+	span.ExitCode = exitCode
+	t.lanes[span.Lane-1] = false
+}
+
+// Spans returns the recorded spans in start order.
+func (t *Trace) Spans() []TraceSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spans := make([]TraceSpan, len(t.spans))
+	for i, span := range t.spans {
+		spans[i] = *span
+	}
+	return spans
+}
+
+// Duration is how long dependents had to wait for a task: until a background
+// task became ready, or until the task exited.
+func (s TraceSpan) Duration() time.Duration {
+	if !s.Ready.IsZero() {
+		return s.Ready.Sub(s.Start)
+	}
+	return s.End.Sub(s.Start)
+}
+
+// CriticalPath returns the chain of dependent tasks with the longest total
+// duration, the time a run would take with unlimited parallelism. The chain
+// starts with the first task to run.
+func (t *Trace) CriticalPath() []TraceSpan {
+	spans := t.Spans()
+	byLabel := make(map[string]TraceSpan)
+	for _, span := range spans {
+		byLabel[span.Label] = span
+	}
+
+	// Longest chain ending in each task, following dependencies backwards
+	total := make(map[string]time.Duration)
+	previous := make(map[string]string)
+	var longest func(label string, visiting map[string]bool) time.Duration
+	longest = func(label string, visiting map[string]bool) time.Duration {
+		if d, ok := total[label]; ok {
+			return d
+		}
+		span := byLabel[label]
+		visiting[label] = true
+		var best time.Duration
+		for _, dep := range span.Dependencies {
+			if _, ran := byLabel[dep]; !ran || visiting[dep] {
+				continue
+			}
+			if d := longest(dep, visiting); previous[label] == "" || d > best {
+				best = d
+				previous[label] = dep
+			}
+		}
+		visiting[label] = false
+		total[label] = best + span.Duration()
+		return total[label]
+	}
+
+	end := ""
+	for _, span := range spans {
+		if d := longest(span.Label, map[string]bool{}); end == "" || d > total[end] {
+			end = span.Label
+		}
+	}
+
+	var path []TraceSpan
+	for label := end; label != ""; label = previous[label] {
+		path = append([]TraceSpan{byLabel[label]}, path...)
+	}
+	return path
+}
+
+// traceEvent is an event of the Chrome trace-event format. Timestamps and
+// durations are in microseconds.
+type traceEvent struct {
+	Name  string                 `json:"name"`
+	Cat   string                 `json:"cat,omitempty"`
+	Phase string                 `json:"ph"`
+	TS    int64                  `json:"ts"`
+	Dur   *int64                 `json:"dur,omitempty"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Scope string                 `json:"s,omitempty"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteJSON writes the trace in the Chrome trace-event JSON format. Each lane
+// is a thread of a single process; the task's own pid is in the arguments.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	spans := t.Spans()
+	micros := func(at time.Time) int64 {
+		return at.Sub(t.start).Microseconds()
+	}
+
+	events := []traceEvent{{Name: "process_name", Phase: "M", PID: 1, Args: map[string]interface{}{"name": "tasks-json-cli"}}}
+	lanes := 0
+	for _, span := range spans {
+		if span.Lane > lanes {
+			lanes = span.Lane
+		}
+	}
+	for lane := 1; lane <= lanes; lane++ {
+		events = append(events, traceEvent{Name: "thread_name", Phase: "M", PID: 1, TID: lane, Args: map[string]interface{}{"name": fmt.Sprintf("lane %d", lane)}})
+	}
+
+	for _, span := range spans {
+		args := map[string]interface{}{"exitCode": span.ExitCode}
+		if span.PID != 0 {
+			args["pid"] = span.PID
+		}
+		if span.Status != "" {
+			args["status"] = span.Status
+		}
+		if span.End.IsZero() {
+			// Still running when the trace was written
+			span.End = time.Now()
+		}
+		dur := micros(span.End) - micros(span.Start)
+		events = append(events, traceEvent{Name: span.Label, Cat: "task", Phase: "X", TS: micros(span.Start), Dur: &dur, PID: 1, TID: span.Lane, Args: args})
+		if !span.Ready.IsZero() {
+			events = append(events, traceEvent{Name: span.Label + " ready", Cat: "task", Phase: "i", TS: micros(span.Ready), PID: 1, TID: span.Lane, Scope: "t"})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"traceEvents":     events,
+		"displayTimeUnit": "ms",
+	})
+}
@@ -0,0 +1,122 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+func TestTrace_CriticalPath(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	trace := &Trace{start: start, spans: []*TraceSpan{
+		{Label: "server", Lane: 1, Start: at(0), Ready: at(100), End: at(1000)},
+		{Label: "codegen", Lane: 2, Start: at(100), End: at(400)},
+		{Label: "compile", Dependencies: []string{"codegen"}, Lane: 2, Start: at(400), End: at(900)},
+		{Label: "lint", Lane: 2, Start: at(900), End: at(950)},
+		{Label: "all", Dependencies: []string{"server", "compile", "lint"}, Lane: 2, Start: at(950), End: at(1000)},
+	}}
+
+	var labels []string
+	for _, span := range trace.CriticalPath() {
+		labels = append(labels, span.Label)
+	}
+	if strings.Join(labels, ",") != "codegen,compile,all" {
+		t.Errorf("expected codegen, compile, all, got %v", labels)
+	}
+
+	if d := trace.spans[0].Duration(); d != 100*time.Millisecond {
+		t.Errorf("expected a background task to count until ready, got %s", d)
+	}
+}
+
+func TestTrace_WriteJSON(t *testing.T) {
+	start := time.Now()
+	trace := &Trace{start: start, spans: []*TraceSpan{
+		{Label: "build", PID: 42, Lane: 1, Start: start.Add(time.Millisecond), End: start.Add(3 * time.Millisecond), ExitCode: 2},
+		{Label: "gen", Lane: 2, Start: start, End: start, Status: "cached"},
+	}}
+
+	var out bytes.Buffer
+	if err := trace.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+
+	var parsed struct {
+		TraceEvents []struct {
+			Name  string                 `json:"name"`
+			Phase string                 `json:"ph"`
+			TS    int64                  `json:"ts"`
+			Dur   int64                  `json:"dur"`
+			TID   int                    `json:"tid"`
+			Args  map[string]interface{} `json:"args"`
+		} `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	var found bool
+	threads := 0
+	for _, event := range parsed.TraceEvents {
+		if event.Name == "thread_name" {
+			threads++
+		}
+		if event.Name == "build" {
+			found = true
+			if event.Phase != "X" || event.TS != 1000 || event.Dur != 2000 || event.TID != 1 {
+				t.Errorf("unexpected build event: %+v", event)
+			}
+			if event.Args["pid"] != float64(42) || event.Args["exitCode"] != float64(2) {
+				t.Errorf("expected pid and exit code in args, got %v", event.Args)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a complete event for build")
+	}
+	if threads != 2 {
+		t.Errorf("expected a thread name per lane, got %d", threads)
+	}
+}
+
+func TestRun_Trace(t *testing.T) {
+	tasks := []config.Task{
+		{Label: "server", Type: "shell", Command: "exec sleep 30", IsBackground: true},
+		{Label: "gen", Type: "shell", Command: "true"},
+		{Label: "build", Type: "shell", Command: "exit 3", DependsOn: []interface{}{"server", "gen"}},
+	}
+	trace := NewTrace()
+	events := NewEventBus()
+	events.Subscribe(trace.Handle)
+
+	err := Run(context.Background(), &tasks[2], tasks, RunOptions{WorkspaceDir: t.TempDir(), Events: events})
+	if err == nil {
+		t.Fatal("expected the failing task to fail the run")
+	}
+
+	spans := trace.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("expected 3 spans, got %d", len(spans))
+	}
+	for _, span := range spans {
+		if span.PID == 0 || span.End.IsZero() {
+			t.Errorf("expected pid and end time for %s, got %+v", span.Label, span)
+		}
+	}
+	if spans[0].Lane == spans[1].Lane {
+		t.Error("expected the background task to get its own lane")
+	}
+	if spans[1].Lane != spans[2].Lane {
+		t.Error("expected sequential tasks to share a lane")
+	}
+	if spans[2].ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %d", spans[2].ExitCode)
+	}
+}
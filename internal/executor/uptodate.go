@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+	"github.com/garaemon/tasks-json-cli/internal/discovery"
+)
+
+// CheckUpToDate reports whether a task can be skipped make-style: it declares
+// x-inputs and x-outputs, every output exists and is not older than any
+// input, and none of its dependencies ran. ran holds the labels of the tasks
+// that ran before. The reason explains the decision either way.
+func CheckUpToDate(task *config.Task, ran map[string]bool, workspaceDir string) (bool, string) {
+	if len(task.Inputs) == 0 || len(task.Outputs) == 0 {
+		return false, "no x-inputs and x-outputs declared"
+	}
+	if IsBackgroundTask(task) {
+		return false, "background task"
+	}
+	for _, dep := range task.GetDependencies() {
+		if ran[dep] {
+			return false, fmt.Sprintf("dependency '%s' ran", dep)
+		}
+	}
+
+	var oldestOutput string
+	var oldestOutputTime time.Time
+	for _, pattern := range task.Outputs {
+		files, err := discovery.ExpandOutputs(workspaceDir, []string{pattern})
+		if err != nil {
+			return false, err.Error()
+		}
+		if len(files) == 0 {
+			return false, fmt.Sprintf("output '%s' is missing", pattern)
+		}
+		for _, rel := range files {
+			modTime, err := modificationTime(workspaceDir, rel)
+			if err != nil {
+				return false, err.Error()
+			}
+			if oldestOutput == "" || modTime.Before(oldestOutputTime) {
+				oldestOutput, oldestOutputTime = rel, modTime
+			}
+		}
+	}
+
+	inputs, err := discovery.ExpandPatterns(workspaceDir, task.Inputs)
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, rel := range inputs {
+		modTime, err := modificationTime(workspaceDir, rel)
+		if err != nil {
+			return false, err.Error()
+		}
+		if modTime.After(oldestOutputTime) {
+			return false, fmt.Sprintf("input '%s' is newer than output '%s'", rel, oldestOutput)
+		}
+	}
+
+	return true, "outputs are newer than inputs"
+}
+
+func modificationTime(workspaceDir, rel string) (time.Time, error) {
+	info, err := os.Stat(filepath.Join(workspaceDir, filepath.FromSlash(rel)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
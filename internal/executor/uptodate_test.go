@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/config"
+)
+
+// touch writes a file and sets its modification time to base plus offset.
+func touch(t *testing.T, workspaceDir, name string, base time.Time, offset time.Duration) {
+	t.Helper()
+	path := filepath.Join(workspaceDir, filepath.FromSlash(name))
+	writeTestFile(t, path, name)
+	if err := os.Chtimes(path, base.Add(offset), base.Add(offset)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckUpToDate(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		files    map[string]time.Duration
+		task     config.Task
+		ran      map[string]bool
+		upToDate bool
+		reason   string
+	}{
+		{
+			name:   "nothing declared",
+			task:   config.Task{Label: "a", Inputs: []string{"src"}},
+			reason: "no x-inputs and x-outputs declared",
+		},
+		{
+			name:     "outputs newer than inputs",
+			files:    map[string]time.Duration{"src/a.c": 0, "src/b.c": time.Minute, "out/a.o": 2 * time.Minute},
+			task:     config.Task{Label: "a", Inputs: []string{"src"}, Outputs: []string{"out/*.o"}},
+			upToDate: true,
+			reason:   "outputs are newer than inputs",
+		},
+		{
+			name:   "input newer than an output",
+			files:  map[string]time.Duration{"src/a.c": 2 * time.Minute, "out/a.o": time.Minute, "out/b.o": 3 * time.Minute},
+			task:   config.Task{Label: "a", Inputs: []string{"src"}, Outputs: []string{"out"}},
+			reason: "input 'src/a.c' is newer than output 'out/a.o'",
+		},
+		{
+			name:   "missing output",
+			files:  map[string]time.Duration{"src/a.c": 0, "out/a.o": time.Minute},
+			task:   config.Task{Label: "a", Inputs: []string{"src"}, Outputs: []string{"out/a.o", "out/b.o"}},
+			reason: "output 'out/b.o' is missing",
+		},
+		{
+			name:   "dependency ran",
+			files:  map[string]time.Duration{"src/a.c": 0, "out/a.o": time.Minute},
+			task:   config.Task{Label: "a", Inputs: []string{"src"}, Outputs: []string{"out"}, DependsOn: "gen"},
+			ran:    map[string]bool{"gen": true},
+			reason: "dependency 'gen' ran",
+		},
+		{
+			name:     "dependency skipped",
+			files:    map[string]time.Duration{"src/a.c": 0, "out/a.o": time.Minute},
+			task:     config.Task{Label: "a", Inputs: []string{"src"}, Outputs: []string{"out"}, DependsOn: "gen"},
+			ran:      map[string]bool{"gen": false},
+			upToDate: true,
+			reason:   "outputs are newer than inputs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspaceDir := t.TempDir()
+			for name, offset := range tt.files {
+				touch(t, workspaceDir, name, base, offset)
+			}
+
+			upToDate, reason := CheckUpToDate(&tt.task, tt.ran, workspaceDir)
+			if upToDate != tt.upToDate || reason != tt.reason {
+				t.Errorf("expected (%v, %q), got (%v, %q)", tt.upToDate, tt.reason, upToDate, reason)
+			}
+		})
+	}
+}
+
+func TestRunAll_SkipsUpToDateTasks(t *testing.T) {
+	workspaceDir := t.TempDir()
+	log := filepath.Join(t.TempDir(), "log")
+	base := time.Now().Add(-time.Hour)
+	touch(t, workspaceDir, "api.proto", base, 0)
+	touch(t, workspaceDir, "gen/api.go", base, time.Minute)
+	touch(t, workspaceDir, "main.go", base, 0)
+	touch(t, workspaceDir, "bin/app", base, 2*time.Minute)
+
+	tasks := []config.Task{
+		{Label: "gen", Type: "shell", Command: "echo gen >> " + log + "; touch gen/api.go", Options: &config.TaskOptions{Cwd: workspaceDir},
+			Inputs: []string{"*.proto"}, Outputs: []string{"gen"}},
+		{Label: "build", Type: "shell", Command: "echo build >> " + log + "; touch bin/app", Options: &config.TaskOptions{Cwd: workspaceDir},
+			Inputs: []string{"*.go"}, Outputs: []string{"bin/app"}, DependsOn: "gen"},
+	}
+
+	run := func(opts RunOptions) string {
+		t.Helper()
+		_ = os.Remove(log)
+		opts.WorkspaceDir = workspaceDir
+		if err := Run(context.Background(), &tasks[1], tasks, opts); err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		data, _ := os.ReadFile(log)
+		return strings.Join(strings.Fields(string(data)), " ")
+	}
+
+	if got := run(RunOptions{}); got != "" {
+		t.Errorf("expected up to date tasks to be skipped, got %q", got)
+	}
+
+	// A dependency that runs makes its dependents run as well
+	touch(t, workspaceDir, "api.proto", base, 30*time.Minute)
+	if got := run(RunOptions{}); got != "gen build" {
+		t.Errorf("expected a changed input to run the task and its dependents, got %q", got)
+	}
+	if got := run(RunOptions{}); got != "" {
+		t.Errorf("expected the tasks to be up to date again, got %q", got)
+	}
+	if got := run(RunOptions{Force: true}); got != "gen build" {
+		t.Errorf("expected --force to run all tasks, got %q", got)
+	}
+}
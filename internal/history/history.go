@@ -0,0 +1,316 @@
+// Package history records the runs of tasks in a small local state store, for
+// rerunning the last one and for statistics.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+)
+
+// maxEntries is how many runs the store keeps; older ones are dropped.
+const maxEntries = 1000
+
+// Entry is one recorded invocation of the run command.
+type Entry struct {
+	Task      string `json:"task"`
+	Workspace string `json:"workspace"`
+	TasksFile string `json:"tasksFile,omitempty"`
+	// File is the value of the ${file} variable
+	File string `json:"file,omitempty"`
+	// Flags holds the command-line flags that were set, by name
+	Flags    map[string]string `json:"flags,omitempty"`
+	Start    time.Time         `json:"start"`
+	Duration time.Duration     `json:"duration"`
+	ExitCode int               `json:"exitCode"`
+	// Tasks holds the results of the target task and its dependencies
+	Tasks []TaskResult `json:"tasks,omitempty"`
+}
+
+// TaskResult is the outcome of one task of a recorded run.
+type TaskResult struct {
+	Label    string        `json:"label"`
+	Duration time.Duration `json:"duration"`
+	ExitCode int           `json:"exitCode"`
+	// Skipped is "up to date" or "cached" for tasks that did not run
+	Skipped string `json:"skipped,omitempty"`
+	// Stopped is set for background tasks stopped at the end of the run
+	Stopped bool `json:"stopped,omitempty"`
+}
+
+// Failed reports whether the task exited by itself with an error.
+func (r TaskResult) Failed() bool {
+	return r.Skipped == "" && !r.Stopped && r.ExitCode != 0
+}
+
+// DefaultPath returns the history file under $XDG_STATE_HOME, which defaults
+// to ~/.local/state.
+func DefaultPath() (string, error) {
+	stateDir := os.Getenv("XDG_STATE_HOME")
+	if stateDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		stateDir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(stateDir, "tasks-json-cli", "history.jsonl"), nil
+}
+
+// Store is a history file with one JSON entry per line, oldest first.
+type Store struct {
+	path string
+}
+
+// NewStore returns the store kept in the file at path.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path returns the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append records a run, dropping the oldest runs beyond the store's limit.
+// Runs finishing at the same time append one after another.
+func (s *Store) Append(entry Entry) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	entries, err := s.Entries()
+	if err != nil || len(entries) <= maxEntries {
+		return err
+	}
+	return s.rewrite(entries[len(entries)-maxEntries:])
+}
+
+// lock takes the lock shared by the processes writing the store. It is kept
+// on a file of its own, since rewrite replaces the history file.
+func (s *Store) lock() (func(), error) {
+	f, err := os.OpenFile(s.path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", s.path, err)
+	}
+	return func() {
+		_ = unlockFile(f)
+		_ = f.Close()
+	}, nil
+}
+
+// rewrite replaces the history file with entries.
+func (s *Store) rewrite(entries []Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), "history-*.tmp")
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(tmp)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Entries returns the recorded runs, oldest first. Lines that cannot be
+// parsed, e.g. from an interrupted write, are skipped.
+func (s *Store) Entries() ([]Entry, error) {
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err == nil {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// Filter selects recorded runs. Zero fields match everything.
+type Filter struct {
+	Workspace string
+	// Task matches runs of the task, or runs in which it ran as a dependency
+	Task   string
+	Failed bool
+	Since  time.Time
+}
+
+// Match reports whether the filter selects entry.
+func (f Filter) Match(entry Entry) bool {
+	if f.Workspace != "" && entry.Workspace != f.Workspace {
+		return false
+	}
+	if f.Failed && entry.ExitCode == 0 {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Start.Before(f.Since) {
+		return false
+	}
+	if f.Task != "" && entry.Task != f.Task {
+		for _, result := range entry.Tasks {
+			if result.Label == f.Task {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// Select returns the entries the filter matches, keeping their order.
+func (f Filter) Select(entries []Entry) []Entry {
+	var selected []Entry
+	for _, entry := range entries {
+		if f.Match(entry) {
+			selected = append(selected, entry)
+		}
+	}
+	return selected
+}
+
+// TaskStats summarizes the recorded results of one task.
+type TaskStats struct {
+	Label    string
+	Runs     int
+	Failures int
+	// Skipped counts the runs in which the task was up to date or cached
+	Skipped int
+	// Total is the duration of the runs that were not skipped
+	Total   time.Duration
+	LastRun time.Time
+}
+
+// Average is the mean duration of the runs that were not skipped.
+func (s TaskStats) Average() time.Duration {
+	executed := s.Runs - s.Skipped
+	if executed == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(executed)
+}
+
+// FailureRate is the fraction of runs that failed.
+func (s TaskStats) FailureRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Runs)
+}
+
+// Stats aggregates the results of every task in entries, sorted by label.
+func Stats(entries []Entry) []TaskStats {
+	byLabel := make(map[string]*TaskStats)
+	for _, entry := range entries {
+		for _, result := range entry.Tasks {
+			stats := byLabel[result.Label]
+			if stats == nil {
+				stats = &TaskStats{Label: result.Label}
+				byLabel[result.Label] = stats
+			}
+			stats.Runs++
+			switch {
+			case result.Skipped != "":
+				stats.Skipped++
+			case result.Failed():
+				stats.Failures++
+				stats.Total += result.Duration
+			default:
+				stats.Total += result.Duration
+			}
+			if entry.Start.After(stats.LastRun) {
+				stats.LastRun = entry.Start
+			}
+		}
+	}
+
+	stats := make([]TaskStats, 0, len(byLabel))
+	for _, s := range byLabel {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Label < stats[j].Label })
+	return stats
+}
+
+// Recorder collects the task results of a run from its events.
+type Recorder struct {
+	mu      sync.Mutex
+	results []TaskResult
+}
+
+// Handle records the events of a run; subscribe it to the run's EventBus.
+func (r *Recorder) Handle(event executor.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch event.Type {
+	case executor.EventTaskSkipped:
+		r.results = append(r.results, TaskResult{Label: event.Task, Skipped: event.Status})
+	case executor.EventTaskFinished:
+		result := TaskResult{Label: event.Task, Duration: event.Duration, ExitCode: -1, Stopped: event.Stopped}
+		if event.ExitCode != nil {
+			result.ExitCode = *event.ExitCode
+		}
+		r.results = append(r.results, result)
+	}
+}
+
+// Results returns the results of the finished tasks in finishing order.
+func (r *Recorder) Results() []TaskResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]TaskResult(nil), r.results...)
+}
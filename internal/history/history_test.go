@@ -0,0 +1,187 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/garaemon/tasks-json-cli/internal/executor"
+)
+
+func TestDefaultPath(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", "/state")
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != filepath.Join("/state", "tasks-json-cli", "history.jsonl") {
+		t.Errorf("unexpected history path %s", path)
+	}
+}
+
+func TestStore_AppendAndEntries(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "state", "history.jsonl"))
+
+	entries, err := store.Entries()
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries before the first run, got %v, %v", entries, err)
+	}
+
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for i, task := range []string{"build", "test"} {
+		entry := Entry{Task: task, Workspace: "/ws", Start: start.Add(time.Duration(i) * time.Minute), Flags: map[string]string{"force": "true"}}
+		if err := store.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A line left behind by an interrupted write is skipped
+	f, err := os.OpenFile(store.Path(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("{\"task\": \"bro")
+	f.Close()
+
+	entries, err = store.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Task != "build" || entries[1].Task != "test" {
+		t.Fatalf("expected build and test in order, got %+v", entries)
+	}
+	if entries[1].Flags["force"] != "true" || !entries[1].Start.Equal(start.Add(time.Minute)) {
+		t.Errorf("expected the entry to round-trip, got %+v", entries[1])
+	}
+}
+
+func TestStore_AppendDropsOldEntries(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	entries := make([]Entry, maxEntries)
+	for i := range entries {
+		entries[i] = Entry{Task: "old"}
+	}
+	if err := store.rewrite(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Append(Entry{Task: "new"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != maxEntries || entries[len(entries)-1].Task != "new" {
+		t.Errorf("expected %d entries ending with the new one, got %d", maxEntries, len(entries))
+	}
+}
+
+func TestStore_ConcurrentAppends(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "history.jsonl"))
+	entries := make([]Entry, maxEntries)
+	for i := range entries {
+		entries[i] = Entry{Task: "old"}
+	}
+	if err := store.rewrite(entries); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Append(Entry{Task: "new"}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, err := store.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := 0
+	for _, entry := range entries {
+		if entry.Task == "new" {
+			added++
+		}
+	}
+	if len(entries) != maxEntries || added != 20 {
+		t.Errorf("expected %d entries with every new one kept, got %d with %d new", maxEntries, len(entries), added)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Task: "ci", Workspace: "/ws", Start: start, ExitCode: 2, Tasks: []TaskResult{{Label: "build"}, {Label: "ci", ExitCode: 2}}}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"empty", Filter{}, true},
+		{"workspace", Filter{Workspace: "/ws"}, true},
+		{"other workspace", Filter{Workspace: "/other"}, false},
+		{"target task", Filter{Task: "ci"}, true},
+		{"dependency", Filter{Task: "build"}, true},
+		{"other task", Filter{Task: "lint"}, false},
+		{"failed", Filter{Failed: true}, true},
+		{"since before", Filter{Since: start.Add(-time.Hour)}, true},
+		{"since after", Filter{Since: start.Add(time.Hour)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(entry); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Start: day, Tasks: []TaskResult{{Label: "build", Duration: time.Second}, {Label: "test", Duration: 4 * time.Second, ExitCode: 1}}},
+		{Start: day.Add(time.Hour), Tasks: []TaskResult{{Label: "build", Duration: 3 * time.Second}, {Label: "test", Duration: 2 * time.Second}}},
+		{Start: day.Add(2 * time.Hour), Tasks: []TaskResult{{Label: "build", Skipped: "up to date"}, {Label: "server", ExitCode: -1, Stopped: true}}},
+	}
+
+	stats := Stats(entries)
+	if len(stats) != 3 {
+		t.Fatalf("expected stats for 3 tasks, got %+v", stats)
+	}
+
+	build, server, test := stats[0], stats[1], stats[2]
+	if build.Runs != 3 || build.Skipped != 1 || build.Average() != 2*time.Second || !build.LastRun.Equal(day.Add(2*time.Hour)) {
+		t.Errorf("unexpected build stats %+v", build)
+	}
+	if test.Failures != 1 || test.FailureRate() != 0.5 || test.Average() != 3*time.Second {
+		t.Errorf("unexpected test stats %+v", test)
+	}
+	if server.Failures != 0 {
+		t.Errorf("expected stopped background tasks not to count as failures, got %+v", server)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	exitCode := 3
+	recorder := &Recorder{}
+	recorder.Handle(executor.Event{Type: executor.EventTaskStarted, Task: "build"})
+	recorder.Handle(executor.Event{Type: executor.EventTaskSkipped, Task: "gen", Status: "cached"})
+	recorder.Handle(executor.Event{Type: executor.EventTaskFinished, Task: "build", ExitCode: &exitCode, Duration: time.Second})
+
+	results := recorder.Results()
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %+v", results)
+	}
+	if results[0].Skipped != "cached" || results[1].ExitCode != 3 || !results[1].Failed() {
+		t.Errorf("unexpected results %+v", results)
+	}
+}